@@ -7,7 +7,12 @@ require (
 	github.com/yuin/goldmark v1.7.11
 )
 
-require github.com/sabhiram/go-gitignore v0.0.0-20210923224102-525f6e181f06
+require (
+	github.com/fsnotify/fsnotify v1.10.1
+	github.com/sabhiram/go-gitignore v0.0.0-20210923224102-525f6e181f06
+)
+
+require golang.org/x/sys v0.26.0 // indirect
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
@@ -16,5 +21,5 @@ require (
 	github.com/rogpeppe/go-internal v1.14.1 // indirect
 	github.com/urfave/cli/v3 v3.3.2
 	go.abhg.dev/goldmark/mermaid v0.5.0
-	gopkg.in/yaml.v3 v3.0.1 // indirect
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"docsncode/internal/cfg"
+	"docsncode/internal/diagnostics"
+	"docsncode/internal/html"
+	"docsncode/internal/models"
+	"docsncode/internal/pathsignorer"
+)
+
+func checkFile(config *cfg.Config, absPathToProjectRoot, absPathToSourceFile string, language cfg.Language, pathsIgnorer pathsignorer.PathsIgnorer) ([]diagnostics.Diagnostic, error) {
+	file, err := os.Open(absPathToSourceFile)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't open file %s: %w", absPathToSourceFile, err)
+	}
+	defer file.Close()
+
+	fileDiagnostics, err := html.CheckFile(file, language, config, absPathToProjectRoot, absPathToSourceFile, pathsIgnorer)
+	if err != nil {
+		return nil, fmt.Errorf("error on checking %s: %w", absPathToSourceFile, err)
+	}
+	return fileDiagnostics, nil
+}
+
+// CheckDocsncode parses all supported files of the project without building results
+// and returns found problems sorted by path and position
+func CheckDocsncode(pathToProjectRoot string, config *cfg.Config, pathsIgnorer pathsignorer.PathsIgnorer) ([]diagnostics.Diagnostic, error) {
+	absPathToProjectRoot, err := filepath.Abs(pathToProjectRoot)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get absolute path for project root directory: %w", err)
+	}
+
+	var result []diagnostics.Diagnostic
+	err = filepath.WalkDir(absPathToProjectRoot, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			log.Printf("error on opening %s: %v", path, err)
+			return err
+		}
+
+		relPath, err := filepath.Rel(absPathToProjectRoot, path)
+		if err != nil {
+			log.Printf("error on building rel path to %s: %v", path, err)
+			return nil
+		}
+		if relPath != "." && pathsIgnorer.ShouldIgnore(models.RelPathFromProjectRoot(relPath)) {
+			log.Printf("paths ignorer said to ignore %s", relPath)
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		language := config.GetLanguageNameIfSupported(filepath.Ext(path))
+		if language == nil {
+			return nil
+		}
+
+		log.Printf("start checking %s", path)
+		fileDiagnostics, err := checkFile(config, absPathToProjectRoot, path, *language, pathsIgnorer)
+		if err != nil {
+			return err
+		}
+		result = append(result, fileDiagnostics...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	diagnostics.Sort(result)
+	return result, nil
+}
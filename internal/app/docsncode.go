@@ -6,14 +6,20 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"sync"
 
+	"docsncode/internal/assets"
 	"docsncode/internal/buildcache"
 	"docsncode/internal/cfg"
+	"docsncode/internal/diagnostics"
 	"docsncode/internal/html"
 	"docsncode/internal/models"
 	"docsncode/internal/paths"
 	"docsncode/internal/pathsignorer"
+	"docsncode/internal/search"
+	"docsncode/internal/theme"
 )
 
 var ErrLanguageNotSupported = errors.New("language is not supported")
@@ -31,39 +37,46 @@ func createFileAndNeededDirs(path string) (*os.File, error) {
 	return file, nil
 }
 
-func buildDocsncodeForFile(absPathToProjectRoot, absPathToSourceFile, absPathToResultDir, absPathToResultFile string, pathsIgnorer pathsignorer.PathsIgnorer) error {
+func buildDocsncodeForFile(config *cfg.Config, absPathToProjectRoot, absPathToSourceFile, absPathToResultDir, absPathToResultFile string, pathsIgnorer pathsignorer.PathsIgnorer, navigationTree *html.NavigationTree, searchIndex *search.Index, pageIncludes *includes) ([]diagnostics.Diagnostic, error) {
 	fileExtension := filepath.Ext(absPathToSourceFile)
 	log.Printf("File extension is: %s", fileExtension)
 
-	language := cfg.GetLanguageNameIfSupported(fileExtension)
+	language := config.GetLanguageNameIfSupported(fileExtension)
 	if language == nil {
-		return ErrLanguageNotSupported
+		return nil, ErrLanguageNotSupported
 	}
 	log.Printf("Building html for %s", *language)
 
 	file, err := os.Open(absPathToSourceFile)
 	if err != nil {
-		return fmt.Errorf("couldn't open file %s: %w", absPathToSourceFile, err)
+		return nil, fmt.Errorf("couldn't open file %s: %w", absPathToSourceFile, err)
 	}
 	defer file.Close()
 
-	html, err := html.BuildHTML(file, *language, absPathToProjectRoot, absPathToSourceFile, absPathToResultDir, absPathToResultFile, pathsIgnorer)
+	html, searchDocument, fileDiagnostics, includedFiles, err := html.BuildHTML(file, *language, config, absPathToProjectRoot, absPathToSourceFile, absPathToResultDir, absPathToResultFile, pathsIgnorer, navigationTree)
 	if err != nil {
-		return fmt.Errorf("error on bulding HTML for %s: %w", absPathToSourceFile, err)
+		return nil, fmt.Errorf("error on bulding HTML for %s: %w", absPathToSourceFile, err)
 	}
 
 	resultFile, err := createFileAndNeededDirs(absPathToResultFile)
 	if err != nil {
-		return fmt.Errorf("couldn't create result file %s: %w", absPathToResultFile, err)
+		return nil, fmt.Errorf("couldn't create result file %s: %w", absPathToResultFile, err)
 	}
 	defer resultFile.Close()
 
 	// TODO: писать сразу в файл с небольшим буффером?
 	_, err = resultFile.Write(html)
 	if err != nil {
-		return fmt.Errorf("error on writing HTML to file: %w", err)
+		return nil, fmt.Errorf("error on writing HTML to file: %w", err)
 	}
-	return nil
+
+	searchIndex.Store(*searchDocument)
+	relPathToSourceFile, err := filepath.Rel(absPathToProjectRoot, absPathToSourceFile)
+	if err != nil {
+		return nil, fmt.Errorf("error on building relative path to %s: %w", absPathToSourceFile, err)
+	}
+	pageIncludes.store(absPathToProjectRoot, models.RelPathFromProjectRoot(relPathToSourceFile), includedFiles)
+	return fileDiagnostics, nil
 }
 
 type buildTask struct {
@@ -74,88 +87,166 @@ type buildTask struct {
 	relPathToSourceFile  models.RelPathFromProjectRoot
 }
 
-func pushBuildTasks(tasksChan chan<- buildTask, pathToProjectRoot, pathToResultDir string, buildCache buildcache.BuildCache, pathsIgnorer pathsignorer.PathsIgnorer) {
-	defer close(tasksChan)
-	filepath.WalkDir(pathToProjectRoot, func(path string, entry os.DirEntry, err error) error {
+// collectBuildTasks returns build tasks for the file or for all supported files inside the directory.
+// It doesn't ask the build cache, so the tasks are returned for all files that will get results.
+func collectBuildTasks(absPathToProjectRoot, absPathToChangedPath, absPathToResultDir string, config *cfg.Config, pathsIgnorer pathsignorer.PathsIgnorer) []buildTask {
+	var tasks []buildTask
+	filepath.WalkDir(absPathToChangedPath, func(path string, entry os.DirEntry, err error) error {
 		if err != nil {
 			log.Printf("error on opening %s: %v", path, err)
-			return err
-		}
-
-		absolutePathToEntry, err := filepath.Abs(path)
-		if err != nil {
-			log.Printf("couldn't get absolute path of %s", path)
-			return filepath.SkipDir
+			return nil
 		}
 
-		if pathToResultDir == absolutePathToEntry {
-			log.Printf("skip walking through %s, because it matches result dir path", absolutePathToEntry)
+		if isPathInside(absPathToResultDir, path) {
 			return filepath.SkipDir
 		}
 
-		var relPathToEntry models.RelPathFromProjectRoot
-		{
-			relPath, err := filepath.Rel(pathToProjectRoot, absolutePathToEntry)
-			if err != nil {
-				log.Printf("error on building rel path to source file: %v", err)
-				return nil
-			}
-			relPathToEntry = models.RelPathFromProjectRoot(relPath)
+		relPath, err := filepath.Rel(absPathToProjectRoot, path)
+		if err != nil {
+			log.Printf("error on building rel path to %s: %v", path, err)
+			return nil
 		}
+		relPathToEntry := models.RelPathFromProjectRoot(relPath)
 
-		if entry.IsDir() {
-			log.Printf("start walking through %s directory", path)
-			if pathsIgnorer.ShouldIgnore(relPathToEntry) {
-				log.Printf("paths ignorer said to ignore the directory")
+		if pathsIgnorer.ShouldIgnore(relPathToEntry) {
+			log.Printf("paths ignorer said to ignore %s", relPathToEntry)
+			if entry.IsDir() {
 				return filepath.SkipDir
 			}
 			return nil
 		}
+		if entry.IsDir() || config.GetLanguageNameIfSupported(filepath.Ext(path)) == nil {
+			return nil
+		}
 
-		log.Printf("start building docsncode for %s", path)
-
-		targetPath, err := paths.ConvertToPathInResultDir(pathToProjectRoot,
-			path,
-			true, // isFile
-			pathToResultDir)
+		targetPath, err := paths.ConvertToPathInResultDir(absPathToProjectRoot, path, true, absPathToResultDir)
 		if err != nil {
 			log.Printf("error on building path to result file for %s: %v", path, err)
 			return nil
 		}
 
-		if !buildCache.ShouldBuild(relPathToEntry) {
-			log.Printf("current result is actual according to build cache")
+		tasks = append(tasks, buildTask{
+			absPathToProjectRoot: absPathToProjectRoot,
+			absPathToSourceFile:  path,
+			absPathToResultDir:   absPathToResultDir,
+			absPathToResultFile:  targetPath,
+			relPathToSourceFile:  relPathToEntry,
+		})
+		return nil
+	})
+	return tasks
+}
+
+// listResultFiles returns sorted paths of the result files that are present in the result dir.
+// Index pages are not included.
+func listResultFiles(absPathToResultDir string) []models.RelPathFromResultDir {
+	var result []models.RelPathFromResultDir
+	filepath.WalkDir(absPathToResultDir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			log.Printf("error on opening %s: %v", path, err)
 			return nil
 		}
-
-		if pathsIgnorer.ShouldIgnore(relPathToEntry) {
-			log.Printf("paths ignorer said to ignore the file")
+		// Static files of the theme can have .html files too
+		if entry.IsDir() && path == filepath.Join(absPathToResultDir, theme.STATIC_DIR_NAME) {
+			return filepath.SkipDir
+		}
+		if entry.IsDir() || !strings.HasSuffix(path, ".html") || entry.Name() == paths.INDEX_PAGE_FILE_NAME {
 			return nil
 		}
 
-		tasksChan <- buildTask{
-			absPathToProjectRoot: pathToProjectRoot,
-			absPathToSourceFile:  absolutePathToEntry,
-			absPathToResultDir:   pathToResultDir,
-			absPathToResultFile:  targetPath,
-			relPathToSourceFile:  relPathToEntry,
+		relPath, err := filepath.Rel(absPathToResultDir, path)
+		if err != nil {
+			log.Printf("error on getting relative path from %s to %s: %s", absPathToResultDir, path, err)
+			return nil
 		}
-
-		log.Printf("pushed build task for path %s", absolutePathToEntry)
+		result = append(result, models.RelPathFromResultDir(relPath))
 		return nil
 	})
+	return result
+}
+
+// writeAssets writes assets for the offline mode. The written files are marked as processed.
+func writeAssets(absPathToResultDir string, processedPaths *paths.ProcessedPaths) {
+	relPathsToAssets, err := assets.WriteAssets(absPathToResultDir)
+	if err != nil {
+		log.Printf("Error on writing assets: %v", err)
+		return
+	}
+	for _, relPath := range relPathsToAssets {
+		processedPaths.Update(relPath)
+	}
+}
+
+// writeThemeStatic writes static files of the theme. The written files are marked as processed.
+func writeThemeStatic(config *cfg.Config, absPathToResultDir string, processedPaths *paths.ProcessedPaths) {
+	if config.Theme == nil {
+		return
+	}
+	relPathsToFiles, err := config.Theme.WriteStatic(absPathToResultDir)
+	if err != nil {
+		log.Printf("Error on writing static files of theme: %v", err)
+	}
+	for _, relPath := range relPathsToFiles {
+		processedPaths.Update(relPath)
+	}
+}
+
+// dumpSearchIndex writes the index with documents of the result files. The written files are marked as processed.
+func dumpSearchIndex(searchIndex *search.Index, absPathToResultDir string, relPathsToResultFiles []models.RelPathFromResultDir, processedPaths *paths.ProcessedPaths) {
+	if len(relPathsToResultFiles) == 0 {
+		log.Printf("there are no results, so search index is not needed")
+		return
+	}
+
+	searchIndex.Retain(relPathsToResultFiles)
+	relPathsToIndexFiles, err := searchIndex.Dump(absPathToResultDir)
+	if err != nil {
+		log.Printf("Error on dumping search index: %v", err)
+		return
+	}
+	for _, relPath := range relPathsToIndexFiles {
+		processedPaths.Update(relPath)
+	}
+}
+
+// getResultFilesOfTasks returns sorted paths of the result files of the tasks
+func getResultFilesOfTasks(tasks []buildTask) []models.RelPathFromResultDir {
+	result := make([]models.RelPathFromResultDir, 0, len(tasks))
+	for _, task := range tasks {
+		relPath, err := filepath.Rel(task.absPathToResultDir, task.absPathToResultFile)
+		if err != nil {
+			log.Printf("error on getting relative path from %s to %s: %s", task.absPathToResultDir, task.absPathToResultFile, err)
+			continue
+		}
+		result = append(result, models.RelPathFromResultDir(relPath))
+	}
+	slices.Sort(result)
+	return result
+}
+
+func getSourceFilesOfTasks(tasks []buildTask) []models.RelPathFromProjectRoot {
+	result := make([]models.RelPathFromProjectRoot, 0, len(tasks))
+	for _, task := range tasks {
+		result = append(result, task.relPathToSourceFile)
+	}
+	return result
 }
 
-func processTasks(tasksChan <-chan buildTask, buildCache buildcache.BuildCache, pathsIgnorer pathsignorer.PathsIgnorer) *paths.ProcessedPaths {
+// processTasks builds the files and returns the problems found in them sorted by path and position
+func processTasks(tasksChan <-chan buildTask, config *cfg.Config, buildCache buildcache.BuildCache, pathsIgnorer pathsignorer.PathsIgnorer, processedPaths *paths.ProcessedPaths, navigationTree *html.NavigationTree, searchIndex *search.Index, pageIncludes *includes) []diagnostics.Diagnostic {
 	wg := sync.WaitGroup{}
-	processedPaths := paths.NewProcessedPaths()
+	var foundDiagnostics []diagnostics.Diagnostic
+	var foundDiagnosticsMutex sync.Mutex
 
 	for task := range tasksChan {
 		wg.Add(1)
 
 		go func() {
 			defer wg.Done()
-			err := buildDocsncodeForFile(task.absPathToProjectRoot, task.absPathToSourceFile, task.absPathToResultDir, task.absPathToResultFile, pathsIgnorer)
+			fileDiagnostics, err := buildDocsncodeForFile(config, task.absPathToProjectRoot, task.absPathToSourceFile, task.absPathToResultDir, task.absPathToResultFile, pathsIgnorer, navigationTree, searchIndex, pageIncludes)
+			foundDiagnosticsMutex.Lock()
+			foundDiagnostics = append(foundDiagnostics, fileDiagnostics...)
+			foundDiagnosticsMutex.Unlock()
 			if err != nil {
 				log.Printf("Error on building result for path=%s, err=%s", task.relPathToSourceFile, err)
 			} else {
@@ -171,7 +262,14 @@ func processTasks(tasksChan <-chan buildTask, buildCache buildcache.BuildCache,
 	}
 
 	wg.Wait()
-	return processedPaths
+	diagnostics.Sort(foundDiagnostics)
+	return foundDiagnostics
+}
+
+func logDiagnostics(foundDiagnostics []diagnostics.Diagnostic) {
+	for _, diagnostic := range foundDiagnostics {
+		log.Println(diagnostic)
+	}
 }
 
 func removeUnrelatedPaths(pathToResultDir string, processedPaths *paths.ProcessedPaths) {
@@ -210,21 +308,92 @@ func removeUnrelatedPaths(pathToResultDir string, processedPaths *paths.Processe
 	})
 }
 
-func BuildDocsncode(pathToProjectRoot, pathToResultDir string, buildCache buildcache.BuildCache, pathsIgnorer pathsignorer.PathsIgnorer) error {
+// BuildDocsncode builds results of the project and returns the problems found in the rebuilt files.
+// Files with cached results are not parsed, so their problems are not returned.
+func BuildDocsncode(pathToProjectRoot, pathToResultDir string, config *cfg.Config, buildCache buildcache.BuildCache, pathsIgnorer pathsignorer.PathsIgnorer) ([]diagnostics.Diagnostic, error) {
 	pathToProjectRoot, err := filepath.Abs(pathToProjectRoot)
 	if err != nil {
-		return fmt.Errorf("couldn't get absolute path for project root directory: %w", err)
+		return nil, fmt.Errorf("couldn't get absolute path for project root directory: %w", err)
 	}
 
 	pathToResultDir, err = filepath.Abs(pathToResultDir)
 	if err != nil {
-		return fmt.Errorf("couldn't get absolute path for result directory: %w", err)
+		return nil, fmt.Errorf("couldn't get absolute path for result directory: %w", err)
+	}
+
+	// The first phase: all files that will get results must be known before building,
+	// because every page has the navigation sidebar with all of them
+	tasks := collectBuildTasks(pathToProjectRoot, pathToProjectRoot, pathToResultDir, config, pathsIgnorer)
+	resultFiles := getResultFilesOfTasks(tasks)
+	navigationTree := html.NewNavigationTree(filepath.Base(pathToProjectRoot), resultFiles)
+
+	// Cached results have the sidebar built for the previous set of files
+	shouldRebuildAll := false
+	if !slices.Equal(resultFiles, listResultFiles(pathToResultDir)) {
+		log.Printf("set of result files is changed, all files will be rebuilt to update navigation")
+		shouldRebuildAll = true
+	}
+	// Cached results were built with the previous config
+	fingerprint, err := getConfigFingerprint(config)
+	if err != nil {
+		return nil, err
+	}
+	if fingerprint != readFingerprint(pathToResultDir) {
+		log.Printf("config is changed, all files will be rebuilt")
+		shouldRebuildAll = true
+	}
+	// Cached results point at the assets of the previous mode
+	if config.Offline != assets.AreAssetsWritten(pathToResultDir) {
+		log.Printf("offline mode is changed, all files will be rebuilt to update links to assets")
+		shouldRebuildAll = true
 	}
 
-	buildTasks := make(chan buildTask, 1)
+	// Cached results can include the files that are changed since the previous build
+	pageIncludes, areIncludesLoaded := loadIncludes(pathToResultDir)
+	if !areIncludesLoaded {
+		log.Printf("included files of cached results are unknown, all files will be rebuilt")
+		shouldRebuildAll = true
+	}
+
+	// Documents of the cached results are taken from the previous search index
+	searchIndex := search.LoadIndex(pathToResultDir)
+
+	// The second phase: building
+	processedPaths := paths.NewProcessedPaths()
+	buildTasks := make(chan buildTask, len(tasks))
+	for _, task := range tasks {
+		relPathToResultFile, err := filepath.Rel(task.absPathToResultDir, task.absPathToResultFile)
+		if err != nil {
+			log.Printf("error on getting relative path from %s to %s: %s", task.absPathToResultDir, task.absPathToResultFile, err)
+			continue
+		}
 
-	go pushBuildTasks(buildTasks, pathToProjectRoot, pathToResultDir, buildCache, pathsIgnorer)
-	processedPaths := processTasks(buildTasks, buildCache, pathsIgnorer)
+		isInSearchIndex := searchIndex.HasDocument(models.RelPathFromResultDir(relPathToResultFile))
+		if !shouldRebuildAll && isInSearchIndex && !buildCache.ShouldBuild(task.relPathToSourceFile) && pageIncludes.areActual(pathToProjectRoot, task.relPathToSourceFile) {
+			log.Printf("result for %s is actual according to build cache", task.relPathToSourceFile)
+			// The result file must not be removed as unrelated
+			processedPaths.Update(models.RelPathFromResultDir(relPathToResultFile))
+			continue
+		}
+		buildTasks <- task
+	}
+	close(buildTasks)
+
+	foundDiagnostics := processTasks(buildTasks, config, buildCache, pathsIgnorer, processedPaths, navigationTree, searchIndex, pageIncludes)
+	// Index pages go first, so they list only the result files
+	buildIndexPages(config, pathToProjectRoot, pathToResultDir, pathsIgnorer, processedPaths, navigationTree)
+	dumpSearchIndex(searchIndex, pathToResultDir, resultFiles, processedPaths)
+	if config.Offline && len(resultFiles) != 0 {
+		writeAssets(pathToResultDir, processedPaths)
+	}
+	if len(resultFiles) != 0 {
+		writeThemeStatic(config, pathToResultDir, processedPaths)
+	}
+	if len(resultFiles) != 0 {
+		writeFingerprint(pathToResultDir, fingerprint, processedPaths)
+		pageIncludes.retain(getSourceFilesOfTasks(tasks))
+		pageIncludes.dump(pathToResultDir, processedPaths)
+	}
 	removeUnrelatedPaths(pathToResultDir, processedPaths)
-	return nil
+	return foundDiagnostics, nil
 }
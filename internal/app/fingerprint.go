@@ -0,0 +1,95 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"docsncode/internal/cfg"
+	"docsncode/internal/models"
+	"docsncode/internal/paths"
+	"docsncode/internal/theme"
+)
+
+// The fingerprint of the config of the last build is stored in this file of the result dir
+const FINGERPRINT_FILE_NAME = ".docsncode_fingerprint"
+
+// configFingerprint has the settings that change the content of the result files,
+// so the cached results must be rebuilt when any of them is changed
+type configFingerprint struct {
+	ExtensionToLanguage               map[string]cfg.Language
+	LanguageToHighlightJSLanguageName map[cfg.Language]string
+	LanguageToCommentSyntax           map[cfg.Language]cfg.CommentSyntax
+	ExtensionToEncoding               map[string]string
+	CommentBlockStartToken            string
+	CommentBlockEndToken              string
+	TabSize                           int
+	ProjectFilesURLPath               string
+	ServerSideHighlighting            bool
+	HighlightStyle                    string
+	CodeDisplay                       cfg.CodeDisplay
+	CodeDisplayThreshold              int
+	Layout                            cfg.Layout
+	HideLicense                       bool
+	ThemeFingerprint                  string
+}
+
+func getConfigFingerprint(config *cfg.Config) (string, error) {
+	themeFingerprint := theme.Default().Fingerprint()
+	if config.Theme != nil {
+		themeFingerprint = config.Theme.Fingerprint()
+	}
+
+	extensionToEncoding := make(map[string]string, len(config.ExtensionToEncoding))
+	for extension, encoding := range config.ExtensionToEncoding {
+		extensionToEncoding[extension] = string(encoding)
+	}
+
+	// Maps are marshaled with sorted keys, so the same config always has the same fingerprint
+	data, err := json.Marshal(configFingerprint{
+		ExtensionToLanguage:               config.ExtensionToLanguage,
+		LanguageToHighlightJSLanguageName: config.LanguageToHighlightJSLanguageName,
+		LanguageToCommentSyntax:           config.LanguageToCommentSyntax,
+		ExtensionToEncoding:               extensionToEncoding,
+		CommentBlockStartToken:            config.CommentBlockStartToken,
+		CommentBlockEndToken:              config.CommentBlockEndToken,
+		TabSize:                           config.TabSize,
+		ProjectFilesURLPath:               config.ProjectFilesURLPath,
+		ServerSideHighlighting:            config.ServerSideHighlighting,
+		HighlightStyle:                    config.HighlightStyle,
+		CodeDisplay:                       config.CodeDisplay,
+		CodeDisplayThreshold:              config.CodeDisplayThreshold,
+		Layout:                            config.Layout,
+		HideLicense:                       config.HideLicense,
+		ThemeFingerprint:                  themeFingerprint,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error on marshaling config fingerprint: %w", err)
+	}
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// readFingerprint returns the fingerprint of the last build or empty string if there is no such build
+func readFingerprint(absPathToResultDir string) string {
+	content, err := os.ReadFile(filepath.Join(absPathToResultDir, FINGERPRINT_FILE_NAME))
+	if err != nil {
+		log.Printf("couldn't read config fingerprint: %v", err)
+		return ""
+	}
+	return string(content)
+}
+
+// writeFingerprint writes the fingerprint of the current build. The written file is marked as processed.
+func writeFingerprint(absPathToResultDir, fingerprint string, processedPaths *paths.ProcessedPaths) {
+	err := os.WriteFile(filepath.Join(absPathToResultDir, FINGERPRINT_FILE_NAME), []byte(fingerprint), 0644)
+	if err != nil {
+		log.Printf("Error on writing config fingerprint: %v", err)
+		return
+	}
+	processedPaths.Update(models.RelPathFromResultDir(FINGERPRINT_FILE_NAME))
+}
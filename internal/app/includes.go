@@ -0,0 +1,136 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"docsncode/internal/models"
+	"docsncode/internal/paths"
+)
+
+// The files included into the pages of the last build are stored in this file of the result dir
+const INCLUDES_FILE_NAME = ".docsncode_includes.json"
+
+// includes are the files included into the pages with the hashes of their contents.
+// Build cache knows only the source files of the pages, so the pages with changed includes are found by the hashes.
+// Methods are goroutine-safe.
+type includes struct {
+	// Source file -> included file -> hash of the included file, empty for missing files
+	entries map[models.RelPathFromProjectRoot]map[models.RelPathFromProjectRoot]string
+	mut     sync.Mutex
+}
+
+func getContentHash(absPath string) string {
+	content, err := os.ReadFile(absPath)
+	if err != nil {
+		return ""
+	}
+	hash := sha256.Sum256(content)
+	return hex.EncodeToString(hash[:])
+}
+
+// loadIncludes reads the includes written by the previous build to the result dir.
+// Returns false if there are no includes or they can't be read, so it's unknown whether cached pages are actual.
+func loadIncludes(absPathToResultDir string) (*includes, bool) {
+	result := &includes{entries: make(map[models.RelPathFromProjectRoot]map[models.RelPathFromProjectRoot]string)}
+
+	content, err := os.ReadFile(filepath.Join(absPathToResultDir, INCLUDES_FILE_NAME))
+	if err != nil {
+		log.Printf("couldn't read includes of previous build: %v", err)
+		return result, false
+	}
+	if err := json.Unmarshal(content, &result.entries); err != nil {
+		log.Printf("error on parsing includes of previous build: %v", err)
+		result.entries = make(map[models.RelPathFromProjectRoot]map[models.RelPathFromProjectRoot]string)
+		return result, false
+	}
+	return result, true
+}
+
+// store replaces the included files of the source file
+func (i *includes) store(absPathToProjectRoot string, relPathToSourceFile models.RelPathFromProjectRoot, includedFiles []models.RelPathFromProjectRoot) {
+	hashes := make(map[models.RelPathFromProjectRoot]string, len(includedFiles))
+	for _, includedFile := range includedFiles {
+		hashes[includedFile] = getContentHash(filepath.Join(absPathToProjectRoot, string(includedFile)))
+	}
+
+	i.mut.Lock()
+	defer i.mut.Unlock()
+
+	if len(hashes) == 0 {
+		delete(i.entries, relPathToSourceFile)
+		return
+	}
+	i.entries[relPathToSourceFile] = hashes
+}
+
+// areActual checks that the files included into the page of the source file are not changed since the last build
+func (i *includes) areActual(absPathToProjectRoot string, relPathToSourceFile models.RelPathFromProjectRoot) bool {
+	i.mut.Lock()
+	hashes := i.entries[relPathToSourceFile]
+	i.mut.Unlock()
+
+	for includedFile, hash := range hashes {
+		if getContentHash(filepath.Join(absPathToProjectRoot, string(includedFile))) != hash {
+			log.Printf("included file %s of %s is changed", includedFile, relPathToSourceFile)
+			return false
+		}
+	}
+	return true
+}
+
+// getDependents returns the source files whose pages include the changed file or files from the changed dir
+func (i *includes) getDependents(absPathToProjectRoot, absPathToChangedPath string) []models.RelPathFromProjectRoot {
+	i.mut.Lock()
+	defer i.mut.Unlock()
+
+	var dependents []models.RelPathFromProjectRoot
+	for sourceFile, hashes := range i.entries {
+		for includedFile := range hashes {
+			if isPathInside(absPathToChangedPath, filepath.Join(absPathToProjectRoot, string(includedFile))) {
+				dependents = append(dependents, sourceFile)
+				break
+			}
+		}
+	}
+	return dependents
+}
+
+// retain removes the includes of the source files that are not in the list
+func (i *includes) retain(relPathsToSourceFiles []models.RelPathFromProjectRoot) {
+	i.mut.Lock()
+	defer i.mut.Unlock()
+
+	sourceFiles := make(map[models.RelPathFromProjectRoot]struct{}, len(relPathsToSourceFiles))
+	for _, relPath := range relPathsToSourceFiles {
+		sourceFiles[relPath] = struct{}{}
+	}
+	for sourceFile := range i.entries {
+		if _, isPresent := sourceFiles[sourceFile]; !isPresent {
+			delete(i.entries, sourceFile)
+		}
+	}
+}
+
+// dump writes the includes to the result dir. The written file is marked as processed.
+func (i *includes) dump(absPathToResultDir string, processedPaths *paths.ProcessedPaths) {
+	i.mut.Lock()
+	defer i.mut.Unlock()
+
+	content, err := json.Marshal(i.entries)
+	if err != nil {
+		log.Printf("Error on encoding includes: %v", err)
+		return
+	}
+	err = os.WriteFile(filepath.Join(absPathToResultDir, INCLUDES_FILE_NAME), content, 0644)
+	if err != nil {
+		log.Printf("Error on writing includes: %v", err)
+		return
+	}
+	processedPaths.Update(models.RelPathFromResultDir(INCLUDES_FILE_NAME))
+}
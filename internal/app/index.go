@@ -0,0 +1,111 @@
+package app
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"docsncode/internal/cfg"
+	"docsncode/internal/html"
+	"docsncode/internal/models"
+	"docsncode/internal/paths"
+	"docsncode/internal/pathsignorer"
+)
+
+type dirContent struct {
+	subdirNames     []string
+	resultFileNames []string
+}
+
+// groupByDirs returns content of the result dir and of every processed dir.
+// The result dir itself has "." path.
+func groupByDirs(processedPaths *paths.ProcessedPaths) map[models.RelPathFromResultDir]*dirContent {
+	contents := map[models.RelPathFromResultDir]*dirContent{".": {}}
+	for _, relPathToDir := range processedPaths.GetProcessedDirs() {
+		contents[relPathToDir] = &dirContent{}
+	}
+
+	// Paths are sorted, so the names are sorted too
+	for _, relPathToDir := range processedPaths.GetProcessedDirs() {
+		parent := contents[models.RelPathFromResultDir(filepath.Dir(string(relPathToDir)))]
+		parent.subdirNames = append(parent.subdirNames, filepath.Base(string(relPathToDir)))
+	}
+	for _, relPathToFile := range processedPaths.GetProcessedFiles() {
+		parent := contents[models.RelPathFromResultDir(filepath.Dir(string(relPathToFile)))]
+		parent.resultFileNames = append(parent.resultFileNames, filepath.Base(string(relPathToFile)))
+	}
+	return contents
+}
+
+func buildIndexPage(config *cfg.Config, absPathToProjectRoot, absPathToResultDir string, relPathToDir models.RelPathFromResultDir, content *dirContent, pathsIgnorer pathsignorer.PathsIgnorer, navigationTree *html.NavigationTree) (string, error) {
+	absPathToSourceDir := filepath.Join(absPathToProjectRoot, string(relPathToDir))
+	absPathToIndexPage := filepath.Join(absPathToResultDir, string(relPathToDir), paths.INDEX_PAGE_FILE_NAME)
+
+	indexHTML, err := html.BuildIndexHTML(config, absPathToProjectRoot, absPathToSourceDir, absPathToResultDir, absPathToIndexPage, content.subdirNames, content.resultFileNames, pathsIgnorer, navigationTree)
+	if err != nil {
+		return "", fmt.Errorf("error on building index page for %s: %w", absPathToSourceDir, err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(absPathToIndexPage), 0755)
+	if err != nil {
+		return "", fmt.Errorf("couldn't create directory for %s: %w", absPathToIndexPage, err)
+	}
+	err = os.WriteFile(absPathToIndexPage, indexHTML, 0644)
+	if err != nil {
+		return "", fmt.Errorf("error on writing index page %s: %w", absPathToIndexPage, err)
+	}
+	return absPathToIndexPage, nil
+}
+
+// buildIndexPages builds index pages for the result dir and every processed dir.
+// The index pages are marked as processed, so they are not removed as unrelated.
+// Returns absolute paths of the built index pages.
+func buildIndexPages(config *cfg.Config, absPathToProjectRoot, absPathToResultDir string, pathsIgnorer pathsignorer.PathsIgnorer, processedPaths *paths.ProcessedPaths, navigationTree *html.NavigationTree) []string {
+	if len(processedPaths.GetProcessedFiles()) == 0 {
+		log.Printf("there are no results, so index pages are not needed")
+		return nil
+	}
+
+	var absPathsToIndexPages []string
+	for relPathToDir, content := range groupByDirs(processedPaths) {
+		absPathToIndexPage, err := buildIndexPage(config, absPathToProjectRoot, absPathToResultDir, relPathToDir, content, pathsIgnorer, navigationTree)
+		if err != nil {
+			log.Printf("Error on building index page for %s: %v", relPathToDir, err)
+			continue
+		}
+		processedPaths.Update(models.RelPathFromResultDir(filepath.Join(string(relPathToDir), paths.INDEX_PAGE_FILE_NAME)))
+		absPathsToIndexPages = append(absPathsToIndexPages, absPathToIndexPage)
+	}
+	return absPathsToIndexPages
+}
+
+// rebuildIndexPages rebuilds all index pages according to the result files that are present in the result dir.
+// It's used in the watch mode, where the processed paths of the whole project are not known.
+func rebuildIndexPages(config *cfg.Config, absPathToProjectRoot, absPathToResultDir string, pathsIgnorer pathsignorer.PathsIgnorer, navigationTree *html.NavigationTree) []string {
+	var absPathsToOldIndexPages []string
+	filepath.WalkDir(absPathToResultDir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			log.Printf("error on opening %s: %v", path, err)
+			return nil
+		}
+		if !entry.IsDir() && entry.Name() == paths.INDEX_PAGE_FILE_NAME {
+			absPathsToOldIndexPages = append(absPathsToOldIndexPages, path)
+		}
+		return nil
+	})
+
+	// Index pages of the directories without results must be removed with the directories
+	for _, path := range absPathsToOldIndexPages {
+		if err := os.Remove(path); err != nil {
+			log.Printf("error on removing index page %s: %v", path, err)
+		}
+		removeEmptyDirs(absPathToResultDir, filepath.Dir(path))
+	}
+
+	processedPaths := paths.NewProcessedPaths()
+	for _, relPathToResultFile := range listResultFiles(absPathToResultDir) {
+		processedPaths.Update(relPathToResultFile)
+	}
+	return buildIndexPages(config, absPathToProjectRoot, absPathToResultDir, pathsIgnorer, processedPaths, navigationTree)
+}
@@ -0,0 +1,288 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"docsncode/internal/buildcache"
+	"docsncode/internal/cfg"
+	"docsncode/internal/html"
+	"docsncode/internal/models"
+	"docsncode/internal/paths"
+	"docsncode/internal/pathsignorer"
+	"docsncode/internal/search"
+	"docsncode/internal/watcher"
+)
+
+type WatchOptions struct {
+	// Changes are collected until there are no new ones during this interval
+	DebounceInterval time.Duration
+	// Use polling watcher even if fsnotify-based watcher is available
+	ForcePolling    bool
+	PollingInterval time.Duration
+	// Is called after each rebuild with paths of rebuilt and removed results. Can be nil
+	OnRebuild func(changedResultPaths []models.RelPathFromResultDir)
+	// Is called when the config file or the ignore file at the project root is changed, the whole project
+	// is rebuilt with the returned settings. If it's nil, the watch stops with an error instead,
+	// so the result isn't left built with stale settings.
+	ReloadSettings func() (*cfg.Config, pathsignorer.PathsIgnorer, error)
+}
+
+func isPathInside(absPathToDir, absPath string) bool {
+	relPath, err := filepath.Rel(absPathToDir, absPath)
+	if err != nil {
+		return false
+	}
+	return relPath != ".." && !strings.HasPrefix(relPath, ".."+string(filepath.Separator))
+}
+
+func newWatcher(absPathToProjectRoot, absPathToResultDir string, pathsIgnorer pathsignorer.PathsIgnorer, options WatchOptions) watcher.Watcher {
+	shouldSkipDir := func(absPathToDir string) bool {
+		if isPathInside(absPathToResultDir, absPathToDir) {
+			return true
+		}
+		relPath, err := filepath.Rel(absPathToProjectRoot, absPathToDir)
+		if err != nil || relPath == "." {
+			return false
+		}
+		return pathsIgnorer.ShouldIgnore(models.RelPathFromProjectRoot(relPath))
+	}
+
+	if !options.ForcePolling {
+		w, err := watcher.NewFSNotifyBasedWatcher(absPathToProjectRoot, shouldSkipDir)
+		if err == nil {
+			log.Printf("will use fsnotify-based watcher")
+			return w
+		}
+		log.Printf("couldn't create fsnotify-based watcher, will use polling watcher: %v", err)
+	}
+	log.Printf("will use polling watcher with interval %s", options.PollingInterval)
+	return watcher.NewPollingWatcher(absPathToProjectRoot, shouldSkipDir, options.PollingInterval)
+}
+
+// isSettingsFileChanged checks whether the config file or the ignore file at the project root is changed
+func isSettingsFileChanged(changedPaths map[string]struct{}, absPathToProjectRoot string) bool {
+	for _, name := range []string{cfg.DEFAULT_CONFIG_FILE_NAME, pathsignorer.DOCSNCODE_IGNORE_FILE_NAME} {
+		if _, isPresent := changedPaths[filepath.Join(absPathToProjectRoot, name)]; isPresent {
+			log.Printf("%s was changed, settings will be reloaded", name)
+			return true
+		}
+	}
+	return false
+}
+
+// removeEmptyDirs removes the directory and its parents while they are empty, but doesn't touch the result dir itself
+func removeEmptyDirs(absPathToResultDir, absPathToDir string) {
+	for absPathToDir != absPathToResultDir && isPathInside(absPathToResultDir, absPathToDir) {
+		entries, err := os.ReadDir(absPathToDir)
+		if err != nil || len(entries) != 0 {
+			return
+		}
+		if err := os.Remove(absPathToDir); err != nil {
+			log.Printf("error on removing empty directory %s: %v", absPathToDir, err)
+			return
+		}
+		absPathToDir = filepath.Dir(absPathToDir)
+	}
+}
+
+// removeResults removes result of the removed source file or results of all files from the removed source directory.
+// Returns the path to the removed result or nil if there was nothing to remove.
+func removeResults(absPathToProjectRoot, absPathToSourcePath, absPathToResultDir string) *string {
+	absPathToResultPath, err := paths.ConvertToPathInResultDir(absPathToProjectRoot, absPathToSourcePath, false, absPathToResultDir)
+	if err != nil {
+		log.Printf("error on building path in result dir for %s: %v", absPathToSourcePath, err)
+		return nil
+	}
+	defer removeEmptyDirs(absPathToResultDir, filepath.Dir(absPathToResultPath))
+
+	if stat, err := os.Stat(absPathToResultPath); err == nil && stat.IsDir() {
+		os.RemoveAll(absPathToResultPath)
+		log.Printf("Deleted directory %s, because its source directory was removed", absPathToResultPath)
+		return &absPathToResultPath
+	}
+
+	absPathToResultFile := absPathToResultPath + ".html"
+	err = os.Remove(absPathToResultFile)
+	if err == nil {
+		log.Printf("Deleted file %s, because its source file was removed", absPathToResultFile)
+		return &absPathToResultFile
+	}
+	if !os.IsNotExist(err) {
+		log.Printf("error on removing result for %s: %v", absPathToSourcePath, err)
+	}
+	return nil
+}
+
+// rebuildChangedPaths returns absolute paths of rebuilt and removed results
+func rebuildChangedPaths(changedPaths map[string]struct{}, absPathToProjectRoot, absPathToResultDir string, config *cfg.Config, buildCache buildcache.BuildCache, pathsIgnorer pathsignorer.PathsIgnorer) []string {
+	var changedResultPaths []string
+	resultFilesBeforeChanges := listResultFiles(absPathToResultDir)
+	isAnyProjectPathChanged := false
+	// A file from a new directory can be reported both by itself and as a part of the directory
+	tasksBySourceFile := make(map[string]buildTask)
+	pageIncludes, _ := loadIncludes(absPathToResultDir)
+	for path := range changedPaths {
+		if !isPathInside(absPathToProjectRoot, path) || isPathInside(absPathToResultDir, path) {
+			continue
+		}
+		isAnyProjectPathChanged = true
+
+		// Pages that include the changed files are rebuilt even if the files don't have own results
+		for _, dependent := range pageIncludes.getDependents(absPathToProjectRoot, path) {
+			log.Printf("%s includes changed path %s", dependent, path)
+			for _, task := range collectBuildTasks(absPathToProjectRoot, filepath.Join(absPathToProjectRoot, string(dependent)), absPathToResultDir, config, pathsIgnorer) {
+				tasksBySourceFile[task.absPathToSourceFile] = task
+			}
+		}
+
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			if removedResultPath := removeResults(absPathToProjectRoot, path, absPathToResultDir); removedResultPath != nil {
+				changedResultPaths = append(changedResultPaths, *removedResultPath)
+			}
+			continue
+		}
+		for _, task := range collectBuildTasks(absPathToProjectRoot, path, absPathToResultDir, config, pathsIgnorer) {
+			tasksBySourceFile[task.absPathToSourceFile] = task
+		}
+	}
+
+	if !isAnyProjectPathChanged {
+		return changedResultPaths
+	}
+
+	tasks := slices.Collect(maps.Values(tasksBySourceFile))
+	resultFiles := listResultFiles(absPathToResultDir)
+	for _, resultFile := range getResultFilesOfTasks(tasks) {
+		if _, isPresent := slices.BinarySearch(resultFiles, resultFile); !isPresent {
+			resultFiles = append(resultFiles, resultFile)
+		}
+	}
+	slices.Sort(resultFiles)
+	navigationTree := html.NewNavigationTree(filepath.Base(absPathToProjectRoot), resultFiles)
+
+	if !slices.Equal(resultFilesBeforeChanges, resultFiles) {
+		log.Printf("set of result files is changed, all files will be rebuilt to update navigation")
+		tasks = collectBuildTasks(absPathToProjectRoot, absPathToProjectRoot, absPathToResultDir, config, pathsIgnorer)
+	}
+	log.Printf("rebuilding %d files", len(tasks))
+
+	tasksChan := make(chan buildTask, len(tasks))
+	for _, task := range tasks {
+		tasksChan <- task
+		changedResultPaths = append(changedResultPaths, task.absPathToResultFile)
+	}
+	close(tasksChan)
+	searchIndex := search.LoadIndex(absPathToResultDir)
+	logDiagnostics(processTasks(tasksChan, config, buildCache, pathsIgnorer, paths.NewProcessedPaths(), navigationTree, searchIndex, pageIncludes))
+	dumpSearchIndex(searchIndex, absPathToResultDir, listResultFiles(absPathToResultDir), paths.NewProcessedPaths())
+	pageIncludes.dump(absPathToResultDir, paths.NewProcessedPaths())
+
+	// Lists of files and READMEs could be changed
+	changedResultPaths = append(changedResultPaths, rebuildIndexPages(config, absPathToProjectRoot, absPathToResultDir, pathsIgnorer, navigationTree)...)
+	return changedResultPaths
+}
+
+func notifyAboutRebuild(onRebuild func([]models.RelPathFromResultDir), absPathToResultDir string, changedResultPaths []string) {
+	if onRebuild == nil || len(changedResultPaths) == 0 {
+		return
+	}
+
+	relPaths := make([]models.RelPathFromResultDir, 0, len(changedResultPaths))
+	for _, path := range changedResultPaths {
+		relPath, err := filepath.Rel(absPathToResultDir, path)
+		if err != nil {
+			log.Printf("error on getting relative path from %s to %s: %s", absPathToResultDir, path, err)
+			continue
+		}
+		relPaths = append(relPaths, models.RelPathFromResultDir(relPath))
+	}
+	onRebuild(relPaths)
+}
+
+// WatchDocsncode builds the whole project and then rebuilds changed files until ctx is done.
+// The caller is responsible for dumping the build cache after return.
+func WatchDocsncode(ctx context.Context, pathToProjectRoot, pathToResultDir string, config *cfg.Config, buildCache buildcache.BuildCache, pathsIgnorer pathsignorer.PathsIgnorer, options WatchOptions) error {
+	absPathToProjectRoot, err := filepath.Abs(pathToProjectRoot)
+	if err != nil {
+		return fmt.Errorf("couldn't get absolute path for project root directory: %w", err)
+	}
+
+	absPathToResultDir, err := filepath.Abs(pathToResultDir)
+	if err != nil {
+		return fmt.Errorf("couldn't get absolute path for result directory: %w", err)
+	}
+
+	// The watcher is started before the initial build to do not miss changes made during it
+	w := newWatcher(absPathToProjectRoot, absPathToResultDir, pathsIgnorer, options)
+	defer func() { w.Close() }()
+
+	foundDiagnostics, err := BuildDocsncode(absPathToProjectRoot, absPathToResultDir, config, buildCache, pathsIgnorer)
+	if err != nil {
+		return err
+	}
+	logDiagnostics(foundDiagnostics)
+	log.Printf("initial build is done, watching %s for changes", absPathToProjectRoot)
+
+	changedPaths := make(map[string]struct{})
+	debounceTimer := time.NewTimer(options.DebounceInterval)
+	debounceTimer.Stop()
+	defer debounceTimer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case path, ok := <-w.Events():
+			if !ok {
+				return fmt.Errorf("watcher stopped unexpectedly")
+			}
+			changedPaths[path] = struct{}{}
+			debounceTimer.Reset(options.DebounceInterval)
+		case <-debounceTimer.C:
+			if isSettingsFileChanged(changedPaths, absPathToProjectRoot) {
+				if options.ReloadSettings == nil {
+					return fmt.Errorf("settings of the project were changed, restart watch to apply them")
+				}
+				config, pathsIgnorer, err = options.ReloadSettings()
+				if err != nil {
+					return fmt.Errorf("error on reloading settings: %w", err)
+				}
+
+				// Ignored dirs are not watched, so the watcher is recreated with the new paths ignorer
+				w.Close()
+				w = newWatcher(absPathToProjectRoot, absPathToResultDir, pathsIgnorer, options)
+				foundDiagnostics, err := BuildDocsncode(absPathToProjectRoot, absPathToResultDir, config, buildCache, pathsIgnorer)
+				if err != nil {
+					return err
+				}
+				logDiagnostics(foundDiagnostics)
+				log.Printf("project is rebuilt with the reloaded settings")
+
+				// Every page could be changed, and a changed dir means all pages inside it
+				var changedResultPaths []string
+				entries, err := os.ReadDir(absPathToResultDir)
+				if err != nil {
+					log.Printf("error on reading result directory: %v", err)
+				}
+				for _, entry := range entries {
+					changedResultPaths = append(changedResultPaths, filepath.Join(absPathToResultDir, entry.Name()))
+				}
+				notifyAboutRebuild(options.OnRebuild, absPathToResultDir, changedResultPaths)
+				changedPaths = make(map[string]struct{})
+				continue
+			}
+
+			changedResultPaths := rebuildChangedPaths(changedPaths, absPathToProjectRoot, absPathToResultDir, config, buildCache, pathsIgnorer)
+			notifyAboutRebuild(options.OnRebuild, absPathToResultDir, changedResultPaths)
+			changedPaths = make(map[string]struct{})
+		}
+	}
+}
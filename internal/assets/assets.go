@@ -0,0 +1,92 @@
+package assets
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+
+	"docsncode/internal/models"
+)
+
+// In the offline mode the assets are written to this directory of the result dir
+const ASSETS_DIR_NAME = "_docsncode_assets"
+
+const (
+	HIGHLIGHT_JS_FILE_NAME  = "highlight.min.js"
+	HIGHLIGHT_CSS_FILE_NAME = "highlight.min.css"
+	MERMAID_JS_FILE_NAME    = "mermaid.min.js"
+)
+
+// Versions loaded from CDNs. Mermaid is pinned to a release of its current major version,
+// so diagrams don't change their look between builds when a new release is published.
+const (
+	HIGHLIGHT_JS_VERSION = "11.11.1"
+	MERMAID_VERSION      = "11.4.1"
+)
+
+// Versions of the vendored libraries used in the offline mode.
+// They lag behind the CDN versions until the files in the vendor dir are updated (see vendor/README.md).
+// The vendored highlight.js has no hljs.highlightAll, so the offline pages use hljs.initHighlighting.
+const (
+	VENDORED_HIGHLIGHT_JS_VERSION = "10.1.1"
+	VENDORED_MERMAID_VERSION      = "10.6.0"
+)
+
+// Are used if the offline mode is disabled
+const (
+	HIGHLIGHT_JS_CDN_URL  = "https://cdnjs.cloudflare.com/ajax/libs/highlight.js/" + HIGHLIGHT_JS_VERSION + "/highlight.min.js"
+	HIGHLIGHT_CSS_CDN_URL = "https://cdnjs.cloudflare.com/ajax/libs/highlight.js/" + HIGHLIGHT_JS_VERSION + "/styles/default.min.css"
+	MERMAID_JS_CDN_URL    = "https://cdn.jsdelivr.net/npm/mermaid@" + MERMAID_VERSION + "/dist/mermaid.min.js"
+)
+
+//go:embed vendor/*.js vendor/*.css
+var vendorFS embed.FS
+
+const vendorDir = "vendor"
+
+// WriteAssets writes the embedded assets to the assets dir of the result dir.
+// Files with the same content are not rewritten. Returns paths of the assets from the result dir.
+func WriteAssets(absPathToResultDir string) ([]models.RelPathFromResultDir, error) {
+	entries, err := fs.ReadDir(vendorFS, vendorDir)
+	if err != nil {
+		return nil, fmt.Errorf("error on reading embedded assets: %w", err)
+	}
+
+	absPathToAssetsDir := filepath.Join(absPathToResultDir, ASSETS_DIR_NAME)
+	err = os.MkdirAll(absPathToAssetsDir, 0755)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create assets directory %s: %w", absPathToAssetsDir, err)
+	}
+
+	relPaths := make([]models.RelPathFromResultDir, 0, len(entries))
+	for _, entry := range entries {
+		content, err := vendorFS.ReadFile(path.Join(vendorDir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("error on reading embedded asset %s: %w", entry.Name(), err)
+		}
+
+		absPathToAsset := filepath.Join(absPathToAssetsDir, entry.Name())
+		relPaths = append(relPaths, models.RelPathFromResultDir(filepath.Join(ASSETS_DIR_NAME, entry.Name())))
+
+		if currentContent, err := os.ReadFile(absPathToAsset); err == nil && bytes.Equal(currentContent, content) {
+			log.Printf("asset %s is actual", absPathToAsset)
+			continue
+		}
+		err = os.WriteFile(absPathToAsset, content, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("error on writing asset %s: %w", absPathToAsset, err)
+		}
+	}
+	return relPaths, nil
+}
+
+// AreAssetsWritten checks whether the result dir has the assets dir, i.e. it was built in the offline mode
+func AreAssetsWritten(absPathToResultDir string) bool {
+	stat, err := os.Stat(filepath.Join(absPathToResultDir, ASSETS_DIR_NAME))
+	return err == nil && stat.IsDir()
+}
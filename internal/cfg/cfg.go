@@ -1,17 +1,52 @@
 package cfg
 
+import (
+	"slices"
+	"strings"
+
+	"docsncode/internal/charset"
+	"docsncode/internal/theme"
+)
+
 type Language string
 
+// CodeDisplay sets which code blocks are collapsed
+type CodeDisplay string
+
+const (
+	CodeDisplayExpanded  CodeDisplay = "expanded"
+	CodeDisplayCollapsed CodeDisplay = "collapsed"
+	// Only code blocks longer than the threshold are collapsed
+	CodeDisplayAuto CodeDisplay = "auto"
+)
+
+var CODE_DISPLAY_MODES = []CodeDisplay{CodeDisplayExpanded, CodeDisplayCollapsed, CodeDisplayAuto}
+
+// Layout sets how comment blocks are placed relative to the code
+type Layout string
+
+const (
+	LayoutStacked Layout = "stacked"
+	// Comment blocks are in the left column next to the code that follows them
+	LayoutSideBySide Layout = "side-by-side"
+)
+
+var LAYOUTS = []Layout{LayoutStacked, LayoutSideBySide}
+
 const (
 	Ada          Language = "Ada"
 	Bash         Language = "Bash"
 	C            Language = "C"
+	Clojure      Language = "Clojure"
 	CoffeeScript Language = "CoffeeScript"
 	CSharp       Language = "C#"
 	Cpp          Language = "C++"
 	D            Language = "D"
+	Elm          Language = "Elm"
+	EmacsLisp    Language = "Emacs Lisp"
 	FSharp       Language = "F#"
 	Go           Language = "Go"
+	Haskell      Language = "Haskell"
 	Java         Language = "Java"
 	JavaScript   Language = "JavaScript"
 	Lua          Language = "Lua"
@@ -22,20 +57,14 @@ const (
 	Ruby         Language = "Ruby"
 	Rust         Language = "Rust"
 	Scala        Language = "Scala"
+	Scheme       Language = "Scheme"
+	SQL          Language = "SQL"
 	Swift        Language = "Swift"
 	Text         Language = "Text"
 	TypeScript   Language = "TypeScript"
 )
 
-type CommentType string
-
-const (
-	CStyle      CommentType = "C-style"
-	PythonStyle CommentType = "Python-style"
-)
-
-// TODO: унести всё в yaml-конфиг
-// TODO: валидация конфига
+// Default settings. They can be extended or overridden by the project config file (see config_file.go)
 var (
 	EXTENSION_TO_LANGUAGE_MAPPING = map[string]Language{
 		".adb":    Ada,
@@ -43,13 +72,19 @@ var (
 		".sh":     Bash,
 		".c":      C,
 		".h":      C,
+		".clj":    Clojure,
+		".cljc":   Clojure,
+		".cljs":   Clojure,
 		".coffee": CoffeeScript,
 		".cpp":    Cpp,
 		".hpp":    Cpp,
 		".cs":     CSharp,
 		".d":      D,
+		".elm":    Elm,
+		".el":     EmacsLisp,
 		".fs":     FSharp,
 		".go":     Go,
+		".hs":     Haskell,
 		".java":   Java,
 		".js":     JavaScript,
 		".lua":    Lua,
@@ -61,6 +96,9 @@ var (
 		".rb":     Ruby,
 		".rs":     Rust,
 		".scala":  Scala,
+		".scm":    Scheme,
+		".ss":     Scheme,
+		".sql":    SQL,
 		".swift":  Swift,
 		".txt":    Text,
 		".ts":     TypeScript,
@@ -70,11 +108,15 @@ var (
 		Ada:          "ada",
 		Bash:         "bash",
 		C:            "c",
+		Clojure:      "clojure",
 		CoffeeScript: "coffeescript",
 		Cpp:          "c++",
 		CSharp:       "csharp",
 		D:            "d",
+		Elm:          "elm",
+		EmacsLisp:    "lisp",
 		Go:           "golang",
+		Haskell:      "haskell",
 		Java:         "java",
 		JavaScript:   "js",
 		Lua:          "lua",
@@ -85,36 +127,229 @@ var (
 		Ruby:         "ruby",
 		Rust:         "rust",
 		Scala:        "scala",
+		Scheme:       "scheme",
+		SQL:          "sql",
 		Swift:        "swift",
 		TypeScript:   "ts",
 	}
 
+	cStyleCommentSyntax = CommentSyntax{
+		SingleLineCommentTokens: []string{"//"},
+		MultilineCommentTokens:  []MultilineCommentTokens{{Start: "/*", End: "*/"}},
+	}
+
+	hashCommentSyntax = CommentSyntax{
+		SingleLineCommentTokens: []string{"#"},
+	}
+
+	haskellStyleCommentSyntax = CommentSyntax{
+		SingleLineCommentTokens: []string{"--"},
+		MultilineCommentTokens:  []MultilineCommentTokens{{Start: "{-", End: "-}"}},
+	}
+
+	lispStyleCommentSyntax = CommentSyntax{
+		SingleLineCommentTokens: []string{";"},
+		MultilineCommentTokens:  []MultilineCommentTokens{{Start: "#|", End: "|#"}},
+	}
+
+	// To support a new language it's enough to add its comment syntax here
+	LANGUAGE_TO_COMMENT_SYNTAX = map[Language]CommentSyntax{
+		Ada:  {SingleLineCommentTokens: []string{"--"}},
+		Bash: hashCommentSyntax,
+		C:    cStyleCommentSyntax,
+		// Clojure doesn't have multiline comments
+		Clojure: {SingleLineCommentTokens: []string{";"}},
+		CoffeeScript: {
+			SingleLineCommentTokens: []string{"#"},
+			MultilineCommentTokens:  []MultilineCommentTokens{{Start: "###", End: "###"}},
+		},
+		CSharp: cStyleCommentSyntax,
+		Cpp:    cStyleCommentSyntax,
+		D: {
+			SingleLineCommentTokens: []string{"//"},
+			MultilineCommentTokens:  []MultilineCommentTokens{{Start: "/*", End: "*/"}, {Start: "/+", End: "+/"}},
+		},
+		Elm:       haskellStyleCommentSyntax,
+		EmacsLisp: {SingleLineCommentTokens: []string{";"}},
+		FSharp: {
+			SingleLineCommentTokens: []string{"//"},
+			MultilineCommentTokens:  []MultilineCommentTokens{{Start: "(*", End: "*)"}},
+		},
+		Go:         cStyleCommentSyntax,
+		Haskell:    haskellStyleCommentSyntax,
+		Java:       cStyleCommentSyntax,
+		JavaScript: cStyleCommentSyntax,
+		Lua: {
+			SingleLineCommentTokens: []string{"--"},
+			MultilineCommentTokens:  []MultilineCommentTokens{{Start: "--[[", End: "]]"}},
+		},
+		ObjectiveC: cStyleCommentSyntax,
+		Perl: {
+			SingleLineCommentTokens: []string{"#"},
+			MultilineCommentTokens:  []MultilineCommentTokens{{Start: "=pod", End: "=cut"}},
+		},
+		PHP: {
+			SingleLineCommentTokens: []string{"//", "#"},
+			MultilineCommentTokens:  []MultilineCommentTokens{{Start: "/*", End: "*/"}},
+		},
+		Python: {
+			SingleLineCommentTokens: []string{"#"},
+			DocstringTokens:         []string{`"""`, "'''"},
+		},
+		Ruby: {
+			SingleLineCommentTokens: []string{"#"},
+			MultilineCommentTokens:  []MultilineCommentTokens{{Start: "=begin", End: "=end"}},
+		},
+		Rust:   cStyleCommentSyntax,
+		Scala:  cStyleCommentSyntax,
+		Scheme: lispStyleCommentSyntax,
+		SQL: {
+			SingleLineCommentTokens: []string{"--"},
+			MultilineCommentTokens:  []MultilineCommentTokens{{Start: "/*", End: "*/"}},
+		},
+		Swift:      cStyleCommentSyntax,
+		Text:       cStyleCommentSyntax,
+		TypeScript: cStyleCommentSyntax,
+	}
+
 	COMMENT_BLOCK_START_TOKEN = "@docsncode"
 	COMMENT_BLOCK_END_TOKEN   = "@docsncode"
+
+	TAB_SIZE = 4
+
+	HIGHLIGHT_STYLE = "default"
+
+	CODE_DISPLAY           = CodeDisplayExpanded
+	CODE_DISPLAY_THRESHOLD = 20
+
+	LAYOUT = LayoutStacked
 )
 
-func GetLanguageNameIfSupported(fileExtension string) *Language {
-	lang, isPresent := EXTENSION_TO_LANGUAGE_MAPPING[fileExtension]
+type MultilineCommentTokens struct {
+	Start string
+	End   string
+}
+
+type CommentSyntax struct {
+	SingleLineCommentTokens []string
+	MultilineCommentTokens  []MultilineCommentTokens
+	// Quotes of the docstrings that can contain comment blocks (e.g. """ in Python)
+	DocstringTokens []string
+}
+
+type Config struct {
+	ExtensionToLanguage               map[string]Language
+	LanguageToHighlightJSLanguageName map[Language]string
+	LanguageToCommentSyntax           map[Language]CommentSyntax
+
+	// Files with other extensions are read with the auto-detected encoding
+	ExtensionToEncoding map[string]charset.Encoding
+
+	CommentBlockStartToken string
+	CommentBlockEndToken   string
+
+	TabSize int
+
+	// If set, links to project files without result files are built as links to this path inside
+	// the result dir, because files outside the result dir can't be reached when it's served over HTTP
+	ProjectFilesURLPath string
+
+	// If set, highlight.js and mermaid are written to the result dir instead of being loaded from CDNs
+	Offline bool
+
+	// If set, code is highlighted while building instead of highlight.js in the browser
+	ServerSideHighlighting bool
+	// Style of the server-side highlighting (see internal/highlight/styles.go)
+	HighlightStyle string
+
+	// If set, the leading license comment of every file is collapsed like the hidden regions
+	HideLicense bool
+
+	CodeDisplay CodeDisplay
+	// Number of lines, code blocks longer than it are collapsed in the auto mode
+	CodeDisplayThreshold int
+
+	Layout Layout
+
+	// Templates and static files of the pages, the default theme is used if nil
+	Theme *theme.Theme
+}
+
+func NewDefaultConfig() *Config {
+	config := &Config{
+		ExtensionToLanguage:               make(map[string]Language),
+		ExtensionToEncoding:               make(map[string]charset.Encoding),
+		LanguageToHighlightJSLanguageName: make(map[Language]string),
+		LanguageToCommentSyntax:           make(map[Language]CommentSyntax),
+		CommentBlockStartToken:            COMMENT_BLOCK_START_TOKEN,
+		CommentBlockEndToken:              COMMENT_BLOCK_END_TOKEN,
+		TabSize:                           TAB_SIZE,
+		HighlightStyle:                    HIGHLIGHT_STYLE,
+		CodeDisplay:                       CODE_DISPLAY,
+		CodeDisplayThreshold:              CODE_DISPLAY_THRESHOLD,
+		Layout:                            LAYOUT,
+	}
+
+	for extension, language := range EXTENSION_TO_LANGUAGE_MAPPING {
+		config.ExtensionToLanguage[extension] = language
+	}
+	for language, commentSyntax := range LANGUAGE_TO_COMMENT_SYNTAX {
+		config.LanguageToCommentSyntax[language] = commentSyntax
+	}
+	for language, name := range LANGUAGE_TO_HIGHLIGHT_JS_LANGUAGE_NAME {
+		config.LanguageToHighlightJSLanguageName[language] = name
+	}
+	return config
+}
+
+func (c *Config) GetEncoding(fileExtension string) charset.Encoding {
+	encoding, isPresent := c.ExtensionToEncoding[fileExtension]
+	if !isPresent {
+		return charset.Auto
+	}
+	return encoding
+}
+
+func (c *Config) GetLanguageNameIfSupported(fileExtension string) *Language {
+	lang, isPresent := c.ExtensionToLanguage[fileExtension]
 	if !isPresent {
 		return nil
 	}
 	return &lang
 }
 
-func GetHighlightJSLanguageName(language Language) *string {
-	name, isPresent := LANGUAGE_TO_HIGHLIGHT_JS_LANGUAGE_NAME[language]
+func (c *Config) GetHighlightJSLanguageName(language Language) *string {
+	name, isPresent := c.LanguageToHighlightJSLanguageName[language]
 	if !isPresent {
 		return nil
 	}
 	return &name
 }
 
-func GetLanguageCommentsType(language Language) CommentType {
-	// TODO(important): support other comment styles
-	switch language {
-	case Bash, CoffeeScript, FSharp, Python, Ruby:
-		return PythonStyle
-	default:
-		return CStyle
+func (c *Config) GetCommentSyntax(language Language) CommentSyntax {
+	return c.LanguageToCommentSyntax[language]
+}
+
+// GetLanguageByName finds the language by its name, highlight.js name or extension without dot
+// (e.g. "Go", "golang" or "go"). The case is ignored.
+func (c *Config) GetLanguageByName(name string) *Language {
+	for language := range c.LanguageToCommentSyntax {
+		if strings.EqualFold(string(language), name) {
+			return &language
+		}
+	}
+	for language, highlightJSName := range c.LanguageToHighlightJSLanguageName {
+		if strings.EqualFold(highlightJSName, name) {
+			return &language
+		}
 	}
+	return c.GetLanguageNameIfSupported("." + strings.ToLower(name))
+}
+
+func IsValidCodeDisplay(codeDisplay CodeDisplay) bool {
+	return slices.Contains(CODE_DISPLAY_MODES, codeDisplay)
+}
+
+func IsValidLayout(layout Layout) bool {
+	return slices.Contains(LAYOUTS, layout)
 }
@@ -0,0 +1,284 @@
+package cfg
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+
+	"docsncode/internal/charset"
+)
+
+const DEFAULT_CONFIG_FILE_NAME = ".docsncode.yaml"
+
+const maxTabSize = 16
+
+// Example of the config file:
+//
+//	tab_size: 2
+//	code_display: auto
+//	code_display_threshold: 30
+//	layout: side-by-side
+//	encodings:
+//	  ".txt": windows-1252
+//	markers:
+//	  block_start: "@doc"
+//	  block_end: "@enddoc"
+//	languages:
+//	  Go:
+//	    highlight_js_name: go
+//	  Terraform:
+//	    extensions: [".tf", ".tfvars"]
+//	    highlight_js_name: terraform
+//	    comments:
+//	      single_line: ["#", "//"]
+//	      multiline:
+//	        - start: "/*"
+//	          end: "*/"
+//	      docstrings: ['"""']
+//
+// Every field is optional. Languages that are not built-in must have extensions and comments.
+type configFile struct {
+	TabSize              *int                      `yaml:"tab_size"`
+	CodeDisplay          *string                   `yaml:"code_display"`
+	CodeDisplayThreshold *int                      `yaml:"code_display_threshold"`
+	Layout               *string                   `yaml:"layout"`
+	Encodings            map[string]string         `yaml:"encodings"`
+	Markers              markersConfig             `yaml:"markers"`
+	Languages            map[string]languageConfig `yaml:"languages"`
+}
+
+type markersConfig struct {
+	BlockStart *string `yaml:"block_start"`
+	BlockEnd   *string `yaml:"block_end"`
+}
+
+type languageConfig struct {
+	Extensions []string `yaml:"extensions"`
+	// Empty string disables highlight for the language
+	HighlightJSName *string `yaml:"highlight_js_name"`
+	// Replaces the whole comment syntax of the language
+	Comments *commentsConfig `yaml:"comments"`
+}
+
+type commentsConfig struct {
+	SingleLine []string                 `yaml:"single_line"`
+	Multiline  []multilineCommentConfig `yaml:"multiline"`
+	Docstrings []string                 `yaml:"docstrings"`
+}
+
+type multilineCommentConfig struct {
+	Start string `yaml:"start"`
+	End   string `yaml:"end"`
+}
+
+func isValidToken(token string) bool {
+	return token != "" && !strings.ContainsFunc(token, unicode.IsSpace)
+}
+
+func isValidExtension(extension string) bool {
+	return len(extension) >= 2 && strings.HasPrefix(extension, ".") && !strings.ContainsAny(extension, `/\`) && !strings.ContainsFunc(extension, unicode.IsSpace)
+}
+
+func isBuiltInLanguage(language Language) bool {
+	_, isPresent := LANGUAGE_TO_COMMENT_SYNTAX[language]
+	return isPresent
+}
+
+func (c *commentsConfig) validate(field string) []error {
+	var errs []error
+	if len(c.SingleLine) == 0 && len(c.Multiline) == 0 && len(c.Docstrings) == 0 {
+		errs = append(errs, fmt.Errorf("%s: at least one comment kind must be set", field))
+	}
+	for i, token := range c.SingleLine {
+		if !isValidToken(token) {
+			errs = append(errs, fmt.Errorf("%s.single_line[%d]: token %q must be non-empty and must not contain spaces", field, i, token))
+		}
+	}
+	for i, token := range c.Docstrings {
+		if !isValidToken(token) {
+			errs = append(errs, fmt.Errorf("%s.docstrings[%d]: token %q must be non-empty and must not contain spaces", field, i, token))
+		}
+	}
+	for i, tokens := range c.Multiline {
+		if tokens.Start == "" || tokens.End == "" {
+			errs = append(errs, fmt.Errorf("%s.multiline[%d]: start and end must be set together", field, i))
+		} else if !isValidToken(tokens.Start) || !isValidToken(tokens.End) {
+			errs = append(errs, fmt.Errorf("%s.multiline[%d]: tokens %q and %q must not contain spaces", field, i, tokens.Start, tokens.End))
+		}
+	}
+	return errs
+}
+
+func (f *configFile) validate() error {
+	var errs []error
+
+	if f.TabSize != nil && (*f.TabSize < 1 || *f.TabSize > maxTabSize) {
+		errs = append(errs, fmt.Errorf("tab_size: must be between 1 and %d, got %d", maxTabSize, *f.TabSize))
+	}
+	if f.CodeDisplay != nil && !IsValidCodeDisplay(CodeDisplay(*f.CodeDisplay)) {
+		errs = append(errs, fmt.Errorf("code_display: must be one of %v, got %q", CODE_DISPLAY_MODES, *f.CodeDisplay))
+	}
+	if f.CodeDisplayThreshold != nil && *f.CodeDisplayThreshold < 1 {
+		errs = append(errs, fmt.Errorf("code_display_threshold: must be positive, got %d", *f.CodeDisplayThreshold))
+	}
+	extensions := make([]string, 0, len(f.Encodings))
+	for extension := range f.Encodings {
+		extensions = append(extensions, extension)
+	}
+	sort.Strings(extensions)
+	for _, extension := range extensions {
+		if !isValidExtension(extension) {
+			errs = append(errs, fmt.Errorf("encodings: %q is not a valid extension, expected something like \".txt\"", extension))
+		}
+		if _, err := charset.ParseEncoding(f.Encodings[extension]); err != nil {
+			errs = append(errs, fmt.Errorf("encodings.%s: %w", extension, err))
+		}
+	}
+	if f.Layout != nil && !IsValidLayout(Layout(*f.Layout)) {
+		errs = append(errs, fmt.Errorf("layout: must be one of %v, got %q", LAYOUTS, *f.Layout))
+	}
+	if f.Markers.BlockStart != nil && !isValidToken(*f.Markers.BlockStart) {
+		errs = append(errs, fmt.Errorf("markers.block_start: %q must be non-empty and must not contain spaces", *f.Markers.BlockStart))
+	}
+	if f.Markers.BlockEnd != nil && !isValidToken(*f.Markers.BlockEnd) {
+		errs = append(errs, fmt.Errorf("markers.block_end: %q must be non-empty and must not contain spaces", *f.Markers.BlockEnd))
+	}
+
+	// Sort languages to get errors in a stable order
+	languageNames := make([]string, 0, len(f.Languages))
+	for name := range f.Languages {
+		languageNames = append(languageNames, name)
+	}
+	sort.Strings(languageNames)
+
+	extensionOwners := make(map[string]string)
+	for _, name := range languageNames {
+		langCfg := f.Languages[name]
+		field := "languages." + name
+
+		if strings.TrimSpace(name) == "" {
+			errs = append(errs, fmt.Errorf("languages: language name must not be empty"))
+			continue
+		}
+
+		if !isBuiltInLanguage(Language(name)) {
+			if len(langCfg.Extensions) == 0 {
+				errs = append(errs, fmt.Errorf("%s: language is not built-in, so extensions must be set", field))
+			}
+			if langCfg.Comments == nil {
+				errs = append(errs, fmt.Errorf("%s: language is not built-in, so comments must be set", field))
+			}
+		}
+
+		for i, extension := range langCfg.Extensions {
+			extensionField := fmt.Sprintf("%s.extensions[%d]", field, i)
+			if !isValidExtension(extension) {
+				errs = append(errs, fmt.Errorf("%s: %q is not a valid extension, expected something like \".go\"", extensionField, extension))
+				continue
+			}
+			if owner, isPresent := extensionOwners[extension]; isPresent {
+				errs = append(errs, fmt.Errorf("%s: extension %q is already used by language %s", extensionField, extension, owner))
+				continue
+			}
+			extensionOwners[extension] = name
+		}
+
+		if langCfg.HighlightJSName != nil && strings.ContainsFunc(*langCfg.HighlightJSName, unicode.IsSpace) {
+			errs = append(errs, fmt.Errorf("%s.highlight_js_name: %q must not contain spaces", field, *langCfg.HighlightJSName))
+		}
+
+		if langCfg.Comments != nil {
+			errs = append(errs, langCfg.Comments.validate(field+".comments")...)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (f *configFile) mergeInto(config *Config) {
+	if f.TabSize != nil {
+		config.TabSize = *f.TabSize
+	}
+	if f.CodeDisplay != nil {
+		config.CodeDisplay = CodeDisplay(*f.CodeDisplay)
+	}
+	if f.CodeDisplayThreshold != nil {
+		config.CodeDisplayThreshold = *f.CodeDisplayThreshold
+	}
+	if f.Layout != nil {
+		config.Layout = Layout(*f.Layout)
+	}
+	for extension, name := range f.Encodings {
+		// The encoding is validated
+		config.ExtensionToEncoding[extension], _ = charset.ParseEncoding(name)
+	}
+	if f.Markers.BlockStart != nil {
+		config.CommentBlockStartToken = *f.Markers.BlockStart
+	}
+	if f.Markers.BlockEnd != nil {
+		config.CommentBlockEndToken = *f.Markers.BlockEnd
+	}
+
+	for name, langCfg := range f.Languages {
+		language := Language(name)
+		for _, extension := range langCfg.Extensions {
+			config.ExtensionToLanguage[extension] = language
+		}
+		if langCfg.HighlightJSName != nil {
+			if *langCfg.HighlightJSName == "" {
+				delete(config.LanguageToHighlightJSLanguageName, language)
+			} else {
+				config.LanguageToHighlightJSLanguageName[language] = *langCfg.HighlightJSName
+			}
+		}
+		if langCfg.Comments != nil {
+			commentSyntax := CommentSyntax{SingleLineCommentTokens: langCfg.Comments.SingleLine, DocstringTokens: langCfg.Comments.Docstrings}
+			for _, tokens := range langCfg.Comments.Multiline {
+				commentSyntax.MultilineCommentTokens = append(commentSyntax.MultilineCommentTokens, MultilineCommentTokens{Start: tokens.Start, End: tokens.End})
+			}
+			config.LanguageToCommentSyntax[language] = commentSyntax
+		}
+	}
+}
+
+// LoadConfig reads the config file and merges it with the default settings.
+// If the file doesn't exist and mustExist is false, the default config is returned.
+func LoadConfig(path string, mustExist bool) (*Config, error) {
+	config := NewDefaultConfig()
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) && !mustExist {
+		log.Printf("do not see config file %s, will use default config", path)
+		return config, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("couldn't open config file %s: %w", path, err)
+	}
+	defer file.Close()
+
+	var f configFile
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	err = decoder.Decode(&f)
+	if err == io.EOF {
+		log.Printf("config file %s is empty, will use default config", path)
+		return config, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error on parsing config file %s: %w", path, err)
+	}
+
+	if err := f.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config file %s:\n%w", path, err)
+	}
+
+	f.mergeInto(config)
+	return config, nil
+}
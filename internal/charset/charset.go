@@ -0,0 +1,112 @@
+package charset
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+type Encoding string
+
+const (
+	// Auto detects the encoding by BOM. Files without BOM are read as UTF-8 if they are valid UTF-8, otherwise as Windows-1252.
+	Auto        Encoding = "auto"
+	UTF8        Encoding = "utf-8"
+	UTF16LE     Encoding = "utf-16le"
+	UTF16BE     Encoding = "utf-16be"
+	Latin1      Encoding = "iso-8859-1"
+	Windows1252 Encoding = "windows-1252"
+)
+
+var ENCODING_ALIASES = map[string]Encoding{
+	"auto":         Auto,
+	"utf-8":        UTF8,
+	"utf8":         UTF8,
+	"utf-16le":     UTF16LE,
+	"utf-16be":     UTF16BE,
+	"iso-8859-1":   Latin1,
+	"latin1":       Latin1,
+	"latin-1":      Latin1,
+	"windows-1252": Windows1252,
+	"cp1252":       Windows1252,
+}
+
+var (
+	utf8BOM    = []byte{0xEF, 0xBB, 0xBF}
+	utf16LEBOM = []byte{0xFF, 0xFE}
+	utf16BEBOM = []byte{0xFE, 0xFF}
+)
+
+// Windows-1252 differs from Latin-1 only in 0x80-0x9F, the undefined bytes are mapped like in Latin-1
+var windows1252HighRunes = [32]rune{
+	'€', 0x81, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0x8D, 'Ž', 0x8F,
+	0x90, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0x9D, 'ž', 'Ÿ',
+}
+
+// ParseEncoding returns the encoding by its name, e.g. "latin1" or "UTF-8"
+func ParseEncoding(name string) (Encoding, error) {
+	encoding, isPresent := ENCODING_ALIASES[strings.ToLower(name)]
+	if !isPresent {
+		return "", fmt.Errorf("unknown encoding %q", name)
+	}
+	return encoding, nil
+}
+
+// DetectEncoding returns the encoding of the data by BOM or by UTF-8 validity
+func DetectEncoding(data []byte) Encoding {
+	switch {
+	case bytes.HasPrefix(data, utf8BOM):
+		return UTF8
+	case bytes.HasPrefix(data, utf16LEBOM):
+		return UTF16LE
+	case bytes.HasPrefix(data, utf16BEBOM):
+		return UTF16BE
+	case utf8.Valid(data):
+		return UTF8
+	}
+	return Windows1252
+}
+
+func decodeUTF16(data []byte, byteOrder binary.ByteOrder) string {
+	units := make([]uint16, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		units = append(units, byteOrder.Uint16(data[i:]))
+	}
+	return string(utf16.Decode(units))
+}
+
+func decodeSingleByte(data []byte, isWindows1252 bool) string {
+	var builder strings.Builder
+	builder.Grow(len(data))
+	for _, b := range data {
+		if isWindows1252 && b >= 0x80 && b <= 0x9F {
+			builder.WriteRune(windows1252HighRunes[b-0x80])
+		} else {
+			builder.WriteRune(rune(b))
+		}
+	}
+	return builder.String()
+}
+
+// Decode converts the data to UTF-8 and strips the BOM.
+// Invalid UTF-8 sequences of UTF-8 data are replaced with U+FFFD.
+func Decode(data []byte, encoding Encoding) (string, Encoding) {
+	if encoding == Auto || encoding == "" {
+		encoding = DetectEncoding(data)
+	}
+
+	switch encoding {
+	case UTF16LE:
+		return decodeUTF16(bytes.TrimPrefix(data, utf16LEBOM), binary.LittleEndian), encoding
+	case UTF16BE:
+		return decodeUTF16(bytes.TrimPrefix(data, utf16BEBOM), binary.BigEndian), encoding
+	case Latin1:
+		return decodeSingleByte(data, false), encoding
+	case Windows1252:
+		return decodeSingleByte(data, true), encoding
+	}
+	return strings.ToValidUTF8(string(bytes.TrimPrefix(data, utf8BOM)), string(utf8.RuneError)), UTF8
+}
@@ -0,0 +1,97 @@
+package diagnostics
+
+import (
+	"fmt"
+	"sort"
+
+	"docsncode/internal/models"
+)
+
+type Severity string
+
+const (
+	// Errors are problems that break the result, e.g. unterminated comment blocks
+	SeverityError Severity = "error"
+	// Warnings are problems of the content, e.g. links to missing files
+	SeverityWarning Severity = "warning"
+)
+
+// Code is a stable identifier of the kind of the problem
+type Code string
+
+const (
+	CodeUnterminatedCommentBlock Code = "unterminated-comment-block"
+	CodeCommentBlockParsing      Code = "comment-block-parsing"
+	CodeMissingCommentToken      Code = "missing-comment-token"
+	CodeUnknownDirective         Code = "unknown-directive"
+	CodeMisplacedDirective       Code = "misplaced-directive"
+	CodeInvalidBlockOption       Code = "invalid-block-option"
+	CodeMissingLinkTarget        Code = "missing-link-target"
+	CodeInvalidHiddenRegion      Code = "invalid-hidden-region"
+	CodeInvalidInclude           Code = "invalid-include"
+)
+
+// Diagnostic is a problem found in a source file. Line and Column are 1-based, Column is counted in bytes.
+type Diagnostic struct {
+	Path     models.RelPathFromProjectRoot
+	Line     int
+	Column   int
+	Severity Severity
+	Code     Code
+	Message  string
+}
+
+// String formats the diagnostic like compilers do: path:line:column: message
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s", d.Path, d.Line, d.Column, d.Message)
+}
+
+// StringWithCode is like String, but the message is prefixed with the severity and the code, e.g. warning[unknown-directive]
+func (d Diagnostic) StringWithCode() string {
+	return fmt.Sprintf("%s:%d:%d: %s[%s]: %s", d.Path, d.Line, d.Column, d.Severity, d.Code, d.Message)
+}
+
+func Sort(diagnostics []Diagnostic) {
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		if diagnostics[i].Path != diagnostics[j].Path {
+			return diagnostics[i].Path < diagnostics[j].Path
+		}
+		if diagnostics[i].Line != diagnostics[j].Line {
+			return diagnostics[i].Line < diagnostics[j].Line
+		}
+		return diagnostics[i].Column < diagnostics[j].Column
+	})
+}
+
+// Collector collects diagnostics for one file. It's not goroutine-safe.
+type Collector struct {
+	path        models.RelPathFromProjectRoot
+	diagnostics []Diagnostic
+}
+
+func NewCollector(path models.RelPathFromProjectRoot) *Collector {
+	return &Collector{path: path}
+}
+
+// Add stores the diagnostic with the path of the collector's file
+func (c *Collector) Add(diagnostic Diagnostic) {
+	diagnostic.Path = c.path
+	c.diagnostics = append(c.diagnostics, diagnostic)
+}
+
+func (c *Collector) Errorf(line, column int, code Code, format string, args ...any) {
+	c.Add(Diagnostic{Line: line, Column: column, Severity: SeverityError, Code: code, Message: fmt.Sprintf(format, args...)})
+}
+
+func (c *Collector) Warnf(line, column int, code Code, format string, args ...any) {
+	c.Add(Diagnostic{Line: line, Column: column, Severity: SeverityWarning, Code: code, Message: fmt.Sprintf(format, args...)})
+}
+
+// Path returns the path of the collector's file
+func (c *Collector) Path() models.RelPathFromProjectRoot {
+	return c.path
+}
+
+func (c *Collector) Diagnostics() []Diagnostic {
+	return c.diagnostics
+}
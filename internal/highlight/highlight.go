@@ -0,0 +1,34 @@
+package highlight
+
+import (
+	"strings"
+	"text/template"
+
+	"docsncode/internal/cfg"
+	"docsncode/internal/lexer"
+)
+
+// Classes are prefixed to do not clash with highlight.js classes and classes of the page
+var TOKEN_TYPE_TO_CSS_CLASS = map[lexer.TokenType]string{
+	lexer.Keyword: "hl-keyword",
+	lexer.Literal: "hl-literal",
+	lexer.Type:    "hl-type",
+	lexer.String:  "hl-string",
+	lexer.Number:  "hl-number",
+	lexer.Comment: "hl-comment",
+}
+
+// Highlight returns HTML-escaped code where tokens are wrapped into spans with classes from TOKEN_TYPE_TO_CSS_CLASS
+func Highlight(code string, language cfg.Language, config *cfg.Config) string {
+	var result strings.Builder
+	for _, token := range lexer.Tokenize(code, language, config) {
+		if token.Type == lexer.Plain {
+			result.WriteString(template.HTMLEscapeString(token.Text))
+			continue
+		}
+		result.WriteString(`<span class="` + TOKEN_TYPE_TO_CSS_CLASS[token.Type] + `">`)
+		result.WriteString(template.HTMLEscapeString(token.Text))
+		result.WriteString("</span>")
+	}
+	return result.String()
+}
@@ -0,0 +1,53 @@
+package highlight
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const DEFAULT_STYLE = "default"
+
+// Styles are embedded into the pages, so they must be small
+var STYLE_NAME_TO_CSS = map[string]string{
+	"default": `.docsncode-highlight {color: #444; background: #f3f3f3;}
+.hl-keyword {color: #333; font-weight: bold;}
+.hl-literal {color: #78a960;}
+.hl-type {color: #397300;}
+.hl-string {color: #880000;}
+.hl-number {color: #880000;}
+.hl-comment {color: #697070;}`,
+	"github": `.docsncode-highlight {color: #24292e; background: #ffffff;}
+.hl-keyword {color: #d73a49;}
+.hl-literal {color: #005cc5;}
+.hl-type {color: #6f42c1;}
+.hl-string {color: #032f62;}
+.hl-number {color: #005cc5;}
+.hl-comment {color: #6a737d;}`,
+	"monokai": `.docsncode-highlight {color: #f8f8f2; background: #272822;}
+.hl-keyword {color: #f92672;}
+.hl-literal {color: #ae81ff;}
+.hl-type {color: #66d9ef;}
+.hl-string {color: #e6db74;}
+.hl-number {color: #ae81ff;}
+.hl-comment {color: #75715e;}`,
+}
+
+// StyleNames returns sorted names of the available styles
+func StyleNames() []string {
+	names := make([]string, 0, len(STYLE_NAME_TO_CSS))
+	for name := range STYLE_NAME_TO_CSS {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func GetStyleCSS(name string) (string, error) {
+	css, isPresent := STYLE_NAME_TO_CSS[name]
+	if !isPresent {
+		return "", fmt.Errorf("unknown highlight style %q, available styles: %s", name, strings.Join(StyleNames(), ", "))
+	}
+	// Padding makes the background of the code blocks look like in highlight.js
+	return "pre code.docsncode-highlight {display: block; overflow-x: auto; padding: 1em;}\n" + css, nil
+}
@@ -0,0 +1,42 @@
+package html
+
+import (
+	"log"
+	"path/filepath"
+
+	"docsncode/internal/assets"
+	"docsncode/internal/cfg"
+)
+
+type assetURLs struct {
+	HighlightJS  string
+	HighlightCSS string
+	MermaidJS    string
+	// Assets are the vendored libraries, see assets.VENDORED_HIGHLIGHT_JS_VERSION
+	IsVendored bool
+}
+
+// getAssetURLs returns CDN URLs or, in the offline mode, paths to the assets relative to the result file
+func getAssetURLs(config *cfg.Config, absPathToResultDir, absPathToResultFile string) assetURLs {
+	if !config.Offline {
+		return assetURLs{
+			HighlightJS:  assets.HIGHLIGHT_JS_CDN_URL,
+			HighlightCSS: assets.HIGHLIGHT_CSS_CDN_URL,
+			MermaidJS:    assets.MERMAID_JS_CDN_URL,
+		}
+	}
+
+	relPathToAssetsDir, err := filepath.Rel(filepath.Dir(absPathToResultFile), filepath.Join(absPathToResultDir, assets.ASSETS_DIR_NAME))
+	if err != nil {
+		log.Printf("error on building relative path to assets from %s: %v", absPathToResultFile, err)
+		relPathToAssetsDir = assets.ASSETS_DIR_NAME
+	}
+	relPathToAssetsDir = filepath.ToSlash(relPathToAssetsDir)
+
+	return assetURLs{
+		HighlightJS:  relPathToAssetsDir + "/" + assets.HIGHLIGHT_JS_FILE_NAME,
+		HighlightCSS: relPathToAssetsDir + "/" + assets.HIGHLIGHT_CSS_FILE_NAME,
+		MermaidJS:    relPathToAssetsDir + "/" + assets.MERMAID_JS_FILE_NAME,
+		IsVendored:   true,
+	}
+}
@@ -0,0 +1,101 @@
+package html
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"docsncode/internal/cfg"
+	"docsncode/internal/lexer"
+)
+
+const maxCodeSummaryLength = 60
+
+// Keywords of different languages that start declarations
+var DECLARATION_KEYWORDS = map[string]bool{
+	"class": true, "const": true, "create": true, "def": true, "defmacro": true, "define": true, "defn": true,
+	"defun": true, "enum": true, "fn": true, "fun": true, "func": true, "function": true, "impl": true,
+	"import": true, "interface": true, "let": true, "module": true, "namespace": true, "object": true,
+	"package": true, "private": true, "procedure": true, "protected": true, "pub": true, "public": true,
+	"static": true, "struct": true, "sub": true, "trait": true, "type": true, "val": true, "var": true,
+}
+
+func isDeclarationKeyword(token lexer.Token) bool {
+	if token.Type != lexer.Keyword {
+		return false
+	}
+	return DECLARATION_KEYWORDS[strings.ToLower(token.Text)]
+}
+
+// Lisp declarations start with a parenthesis
+func isIndentation(text string) bool {
+	return strings.Trim(text, " \t(") == ""
+}
+
+// getFirstDeclaration returns the first line of the code that starts with a declaration keyword (e.g. "func main() {").
+// If there are no such lines, the first non-empty line is returned.
+func getFirstDeclaration(content string, language cfg.Language, config *cfg.Config) string {
+	position := 0
+	isLineStart := true
+	for _, token := range lexer.Tokenize(content, language, config) {
+		if isLineStart && isDeclarationKeyword(token) {
+			lineStart := strings.LastIndexByte(content[:position], '\n') + 1
+			lineEnd := strings.IndexByte(content[position:], '\n')
+			if lineEnd == -1 {
+				return content[lineStart:]
+			}
+			return content[lineStart : position+lineEnd]
+		}
+
+		if token.Type != lexer.Plain {
+			isLineStart = false
+		} else if newLineIndex := strings.LastIndexByte(token.Text, '\n'); newLineIndex != -1 {
+			isLineStart = isIndentation(token.Text[newLineIndex+1:])
+		} else {
+			isLineStart = isLineStart && isIndentation(token.Text)
+		}
+		position += len(token.Text)
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		if strings.TrimSpace(line) != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+func getCodeSummary(codeBlock block, language cfg.Language, config *cfg.Config) string {
+	summary := strings.TrimFunc(getFirstDeclaration(string(codeBlock.Content), language, config), unicode.IsSpace)
+	if utf8.RuneCountInString(summary) > maxCodeSummaryLength {
+		summary = string([]rune(summary)[:maxCodeSummaryLength]) + "…"
+	}
+
+	linesCnt := len(codeBlock.LineNumbers)
+	if linesCnt == 1 {
+		return fmt.Sprintf("%s (1 line)", summary)
+	}
+	return fmt.Sprintf("%s (%d lines)", summary, linesCnt)
+}
+
+// collapseCodeBlocks wraps code blocks into expanders according to the code display mode.
+// Code blocks that are already collapsed (e.g. hidden regions) keep their summaries.
+func collapseCodeBlocks(blocks []block, language cfg.Language, config *cfg.Config) {
+	if config.CodeDisplay == cfg.CodeDisplayExpanded {
+		return
+	}
+
+	for i := range blocks {
+		if blocks[i].Type != code || blocks[i].IsCollapsed {
+			continue
+		}
+		if config.CodeDisplay == cfg.CodeDisplayAuto && len(blocks[i].LineNumbers) <= config.CodeDisplayThreshold {
+			continue
+		}
+		log.Printf("Collapse code block with %d lines", len(blocks[i].LineNumbers))
+		blocks[i].IsCollapsed = true
+		blocks[i].Summary = getCodeSummary(blocks[i], language, config)
+	}
+}
@@ -0,0 +1,77 @@
+package html
+
+import (
+	"strings"
+	"text/template"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+
+	"docsncode/internal/cfg"
+	"docsncode/internal/highlight"
+)
+
+// Fenced code blocks of comment blocks have this class to be distinguishable from the code blocks of the file
+const COMMENT_CODE_BLOCK_CLASS = "docsncode-comment-code"
+
+// fencedCodeBlockRenderer renders fenced code blocks of comment blocks.
+// The code is highlighted on the server side or marked with the highlight.js language name.
+type fencedCodeBlockRenderer struct {
+	config *cfg.Config
+}
+
+func (r *fencedCodeBlockRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindFencedCodeBlock, r.renderFencedCodeBlock)
+}
+
+func (r *fencedCodeBlockRenderer) renderFencedCodeBlock(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	codeBlock := node.(*ast.FencedCodeBlock)
+
+	var code strings.Builder
+	lines := codeBlock.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		segment := lines.At(i)
+		code.Write(segment.Value(source))
+	}
+
+	var languageName string
+	if codeBlock.Info != nil {
+		languageName = string(codeBlock.Language(source))
+	}
+	writeCommentCode(w, code.String(), languageName, r.config)
+	return ast.WalkSkipChildren, nil
+}
+
+// writeCommentCode writes the code of a comment block (e.g. of a fenced code block).
+// The language can be set with a language name, a highlight.js name or an extension.
+func writeCommentCode(w util.BufWriter, code, languageName string, config *cfg.Config) {
+	language := config.GetLanguageByName(languageName)
+
+	var classes []string
+	var content string
+	if config.ServerSideHighlighting {
+		classes = append(classes, "docsncode-highlight")
+		if language != nil {
+			content = highlight.Highlight(code, *language, config)
+		} else {
+			content = template.HTMLEscapeString(code)
+		}
+	} else {
+		if language != nil && config.GetHighlightJSLanguageName(*language) != nil {
+			classes = append(classes, "language-"+*config.GetHighlightJSLanguageName(*language))
+		} else if languageName != "" {
+			classes = append(classes, "language-"+languageName)
+		}
+		content = template.HTMLEscapeString(code)
+	}
+
+	_, _ = w.WriteString(`<pre class="` + COMMENT_CODE_BLOCK_CLASS + `"><code`)
+	if len(classes) != 0 {
+		_, _ = w.WriteString(` class="` + template.HTMLEscapeString(strings.Join(classes, " ")) + `"`)
+	}
+	_, _ = w.WriteString(">" + content + "</code></pre>\n")
+}
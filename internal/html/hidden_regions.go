@@ -0,0 +1,114 @@
+package html
+
+import (
+	"fmt"
+	"html/template"
+	"log"
+	"strings"
+
+	"docsncode/internal/cfg"
+	"docsncode/internal/lexer"
+)
+
+// Words that mark the leading comment of a file as a license header
+var LICENSE_COMMENT_KEYWORDS = []string{"copyright", "license", "licence", "spdx-license-identifier"}
+
+func getHideStartDirective(config *cfg.Config) string {
+	return config.CommentBlockStartToken + "-hide-start"
+}
+
+func getHideEndDirective(config *cfg.Config) string {
+	return config.CommentBlockStartToken + "-hide-end"
+}
+
+func isCodeDirective(directive string, config *cfg.Config) bool {
+	return directive == getHideStartDirective(config) || directive == getHideEndDirective(config)
+}
+
+func getHiddenLinesSummary(linesCnt int) string {
+	if linesCnt == 1 {
+		return "1 line hidden"
+	}
+	return fmt.Sprintf("%d lines hidden", linesCnt)
+}
+
+// appendCodeBlock appends the code block, if it has anything besides spaces.
+// Hidden code blocks are collapsed into the "N lines hidden" expander.
+func appendCodeBlock(blocks []block, content []byte, lineNumbers []int, isHidden bool) []block {
+	if !isCodeBlockContentAllowed(content) {
+		return blocks
+	}
+	codeBlock := block{
+		Type:            code,
+		Content:         template.HTML(content),
+		IndentSpacesCnt: 0,
+		LineNumbers:     lineNumbers,
+	}
+	if isHidden {
+		codeBlock.IsCollapsed = true
+		codeBlock.Summary = getHiddenLinesSummary(len(lineNumbers))
+	}
+	return append(blocks, codeBlock)
+}
+
+// findLicenseComment returns the range [first, last] of the lines of the first code block,
+// which are the leading comment with license words. Shebang and empty lines before the comment are skipped,
+// the comment ends at the first empty line, so e.g. a package doc comment after the license stays visible.
+func findLicenseComment(content string, language cfg.Language, config *cfg.Config) (int, int, bool) {
+	offset := 0
+	if strings.HasPrefix(content, "#!") {
+		offset = strings.IndexByte(content, '\n') + 1
+		if offset == 0 {
+			return 0, 0, false
+		}
+	}
+
+	commentStart, commentEnd := -1, -1
+	var commentText strings.Builder
+	position := offset
+	for _, token := range lexer.Tokenize(content[offset:], language, config) {
+		if token.Type == lexer.Comment {
+			if commentStart == -1 {
+				commentStart = position
+			}
+			commentEnd = position + len(token.Text)
+			commentText.WriteString(token.Text)
+		} else if token.Type != lexer.Plain || strings.TrimSpace(token.Text) != "" {
+			break
+		} else if commentStart != -1 && strings.Count(token.Text, "\n") > 1 {
+			break
+		}
+		position += len(token.Text)
+	}
+	if commentStart == -1 {
+		return 0, 0, false
+	}
+
+	lowerCommentText := strings.ToLower(commentText.String())
+	for _, keyword := range LICENSE_COMMENT_KEYWORDS {
+		if strings.Contains(lowerCommentText, keyword) {
+			return strings.Count(content[:commentStart], "\n"), strings.Count(content[:commentEnd], "\n"), true
+		}
+	}
+	return 0, 0, false
+}
+
+// hideLicenseComment collapses the license header of the file like a hidden region
+func hideLicenseComment(blocks []block, language cfg.Language, config *cfg.Config) []block {
+	if len(blocks) == 0 || blocks[0].Type != code || blocks[0].IsCollapsed {
+		return blocks
+	}
+	firstLine, lastLine, found := findLicenseComment(string(blocks[0].Content), language, config)
+	if !found {
+		return blocks
+	}
+	log.Printf("Hide license comment at lines %d-%d of the first code block", firstLine+1, lastLine+1)
+
+	lines := strings.Split(string(blocks[0].Content), "\n")
+	lineNumbers := blocks[0].LineNumbers
+	result := make([]block, 0, len(blocks)+2)
+	result = appendCodeBlock(result, []byte(strings.Join(lines[:firstLine], "\n")), lineNumbers[:firstLine], false)
+	result = appendCodeBlock(result, []byte(strings.Join(lines[firstLine:lastLine+1], "\n")), lineNumbers[firstLine:lastLine+1], true)
+	result = appendCodeBlock(result, []byte(strings.Join(lines[lastLine+1:], "\n")), lineNumbers[lastLine+1:], false)
+	return append(result, blocks[1:]...)
+}
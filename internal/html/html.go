@@ -1,55 +1,51 @@
 package html
 
 import (
-	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
+	"html/template"
 	"log"
 	"os"
-	"text/template"
+	"path/filepath"
+	"slices"
+	"strings"
 	"unicode"
-	"unicode/utf8"
 
 	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
 	"github.com/yuin/goldmark/util"
 	"go.abhg.dev/goldmark/mermaid"
 
 	"docsncode/internal/cfg"
+	"docsncode/internal/diagnostics"
+	"docsncode/internal/highlight"
+	"docsncode/internal/lexer"
+	"docsncode/internal/models"
 	"docsncode/internal/parsers"
 	"docsncode/internal/pathsignorer"
+	"docsncode/internal/search"
+	"docsncode/internal/theme"
 )
 
-// TODO: перестать использовать числовые константы в шаблонах (Code и Comment вместо 0 и 1)
 // TODO: не подключать highlight.js, если в файле не будет блоков с кодом
-// TODO: вынести настройку tab-size в конфиг
-var htmlTemplate = template.Must(template.New("docsncode").Parse(`<!DOCTYPE html>
-<html>
-<head>
-	<link rel="stylesheet" href="https://cdnjs.cloudflare.com/ajax/libs/highlight.js/11.11.1/styles/default.min.css">
-	<script src="https://cdnjs.cloudflare.com/ajax/libs/highlight.js/11.11.1/highlight.min.js"></script>
-	<style>pre {tab-size: 4ch;}</style>
-</head>
-<body>
-    {{range .Blocks}}
-        {{if eq .Type 0}}
-			{{if $.HighlightJsLanguageName }}
-				<pre><code class="language-{{$.HighlightJsLanguageName}}">{{.Content}}</code></pre>
-			{{else}}
-				<pre><code>{{.Content}}</code></pre>
-			{{end}}
-        {{else if eq .Type 1}}
-			<div style="padding-left: calc({{.IndentSpacesCnt}}ch + 1em); font-size:12px;">{{.Content}}</div>
-		{{end}}
-	{{end}}
-	<script>hljs.highlightAll();</script>
-</body>
-</html>
-`))
 
+// htmlTemplateData is passed to the page template of the theme (see docs/themes.md)
 type htmlTemplateData struct {
-	Blocks                  []block
-	HighlightJsLanguageName *string
+	templateContext
+	// Path to the source file from the project root with forward slashes, e.g. internal/html/html.go
+	Path     string
+	Language string
+	Blocks   []block
+	// Is set only in the side-by-side layout
+	Sections []section
+	TabSize  int
+	// Nil if the sidebar is not needed
+	Navigation *navigationData
+	Assets     assetURLs
+	// Is set only if the code is highlighted on the server side
+	HighlightStyleCSS template.CSS
 }
 
 type blockType int
@@ -61,23 +57,58 @@ const (
 
 // TODO: растащить на две структуры
 type block struct {
-	Type            blockType
-	Content         string
+	Type blockType
+	// Source code of code blocks until they are escaped or highlighted, then it's HTML like the content of comment blocks
+	Content         template.HTML
 	IndentSpacesCnt int
+	// Are used by the search index, only comment blocks have them
+	Headings []string
+	Text     string
+	// Numbers of the source lines of the block. Lines of comment blocks include the marker lines.
+	LineNumbers []int
+	// Are set by the block options, only comment blocks have them
+	ID    string
+	Class string
+	// Code blocks are collapsed by hide markers or by the code display mode
+	IsCollapsed bool
+	// Is shown instead of collapsed code
+	Summary string
+	// Class of the code element, is set for highlighting
+	CodeClass string
 }
 
-func convertMarkdownToHTML(md []byte, absPathToProjectRoot, absPathToCurrentFile, absPathToResultDir, absPathToResultFile string, pathsIgnorer pathsignorer.PathsIgnorer) ([]byte, error) {
+func (b block) IsCode() bool {
+	return b.Type == code
+}
+
+func (b block) IsComment() bool {
+	return b.Type == comment
+}
+
+func convertMarkdownToHTML(md []byte, sourceMap *markdownSourceMap, config *cfg.Config, absPathToProjectRoot, absPathToCurrentFile, absPathToResultDir, absPathToResultFile string, pathsIgnorer pathsignorer.PathsIgnorer, diagnosticsCollector *diagnostics.Collector, includedFiles *includedFiles) ([]byte, error) {
+	linksResolver := &linksResolverTransformer{
+		config:               config,
+		absPathToProjectRoot: absPathToProjectRoot,
+		absPathToCurrentFile: absPathToCurrentFile,
+		absPathToResultDir:   absPathToResultDir,
+		absPathToResultFile:  absPathToResultFile,
+		pathsIgnorer:         pathsIgnorer,
+		sourceMap:            sourceMap,
+		diagnosticsCollector: diagnosticsCollector,
+		includedFiles:        includedFiles,
+	}
 	converter := goldmark.New(
-		goldmark.WithExtensions(&mermaid.Extender{RenderMode: mermaid.RenderModeClient}),
+		goldmark.WithExtensions(&mermaid.Extender{RenderMode: mermaid.RenderModeClient, MermaidURL: getAssetURLs(config, absPathToResultDir, absPathToResultFile).MermaidJS}),
 		goldmark.WithParserOptions(
-			parser.WithASTTransformers(util.Prioritized(&linksResolverTransformer{
-				absPathToProjectRoot: absPathToProjectRoot,
-				absPathToCurrentFile: absPathToCurrentFile,
-				absPathToResultDir:   absPathToResultDir,
-				absPathToResultFile:  absPathToResultFile,
-				pathsIgnorer:         pathsIgnorer,
-			}, 0)),
+			parser.WithASTTransformers(util.Prioritized(linksResolver, 0)),
+			// The paragraph parser has priority 1000, the include directive must be checked before it
+			parser.WithBlockParsers(util.Prioritized(&includeBlockParser{linksResolver: linksResolver}, 100)),
 		),
+		// The default renderer has priority 1000, renderers with smaller priorities override it
+		goldmark.WithRendererOptions(renderer.WithNodeRenderers(
+			util.Prioritized(&fencedCodeBlockRenderer{config: config}, 100),
+			util.Prioritized(&includeRenderer{config: config}, 100),
+		)),
 	)
 
 	var buf bytes.Buffer
@@ -87,102 +118,255 @@ func convertMarkdownToHTML(md []byte, absPathToProjectRoot, absPathToCurrentFile
 	return buf.Bytes(), nil
 }
 
-func escapeHTMLInCodeBlocks(blocks []block) {
+// escapeHTMLInCodeBlocks marks code blocks with the highlight.js language to be highlighted in the browser
+func escapeHTMLInCodeBlocks(blocks []block, language cfg.Language, config *cfg.Config) {
+	var codeClass string
+	if highlightJsLanguageName := config.GetHighlightJSLanguageName(language); highlightJsLanguageName != nil {
+		codeClass = "language-" + *highlightJsLanguageName
+	}
 	for i := range blocks {
 		if blocks[i].Type != code {
 			continue
 		}
-		blocks[i].Content = template.HTMLEscapeString(blocks[i].Content)
+		blocks[i].Content = template.HTML(template.HTMLEscapeString(string(blocks[i].Content)))
+		blocks[i].CodeClass = codeClass
 	}
 }
 
-func buildCommentParsersByLanguage(language cfg.Language) []parsers.CommentParser {
-	commentType := cfg.GetLanguageCommentsType(language)
-	switch commentType {
-	case cfg.CStyle:
-		return []parsers.CommentParser{
-			parsers.NewCStyleSingleLineCommentBlockParser(),
-			parsers.NewCStyleMultilineCommentBlockParser(),
-		}
-	case cfg.PythonStyle:
-		return []parsers.CommentParser{
-			parsers.NewPythonStyleSingleLineCommentBlockParser(),
+// highlightCodeBlocks is used instead of escapeHTMLInCodeBlocks in the server-side highlighting mode
+func highlightCodeBlocks(blocks []block, language cfg.Language, config *cfg.Config) {
+	for i := range blocks {
+		if blocks[i].Type != code {
+			continue
 		}
+		blocks[i].Content = template.HTML(highlight.Highlight(string(blocks[i].Content), language, config))
+		blocks[i].CodeClass = "docsncode-highlight"
+	}
+}
+
+func buildCommentParsersByLanguage(config *cfg.Config, language cfg.Language) []parsers.CommentParser {
+	commentSyntax := config.GetCommentSyntax(language)
+
+	commentParsers := make([]parsers.CommentParser, 0, len(commentSyntax.DocstringTokens)+len(commentSyntax.MultilineCommentTokens)+len(commentSyntax.SingleLineCommentTokens))
+	for _, token := range commentSyntax.DocstringTokens {
+		commentParsers = append(commentParsers, parsers.NewDocstringCommentBlockParser(config, token))
+	}
+	// Multiline comment parsers go first, because their start tokens are usually longer (e.g. --[[ and --)
+	for _, tokens := range commentSyntax.MultilineCommentTokens {
+		commentParsers = append(commentParsers, parsers.NewMultilineCommentBlockParser(config, tokens.Start, tokens.End))
+	}
+	for _, token := range commentSyntax.SingleLineCommentTokens {
+		commentParsers = append(commentParsers, parsers.NewSingleLineCommentBlockParser(config, token))
+	}
+	if len(commentParsers) == 0 {
+		// TODO: make log error
+		log.Printf("Language %s doesn't have comment syntax", language)
 	}
-	// TODO: make log error
-	log.Printf("Unexpected commentType=%s", commentType)
-	return []parsers.CommentParser{}
+	return commentParsers
 }
 
+// isCodeBlockContentAllowed returns false for code blocks of spaces only.
+// Invalid UTF-8 sequences are content too, so such code is not lost.
 func isCodeBlockContentAllowed(content []byte) bool {
-	if content == nil {
-		return false
+	return bytes.ContainsFunc(content, func(r rune) bool {
+		return !unicode.IsSpace(r)
+	})
+}
+
+// getDirective returns the directive name (e.g. "@docsncode-include") if the text starts with it.
+// Directives are the block start marker followed by a dash and a name.
+func getDirective(text string, config *cfg.Config) *string {
+	if !strings.HasPrefix(text, config.CommentBlockStartToken+"-") {
+		return nil
 	}
+	directive := strings.Fields(text)[0]
+	return &directive
+}
 
-	for i := 0; i < len(content); {
-		r, sz := utf8.DecodeRune(content[i:])
-		if r == utf8.RuneError {
-			log.Printf("Got RunError")
-			return false
+// findDirectiveInCode returns the directive placed in a comment outside of comment blocks and its column
+func findDirectiveInCode(line string, commentSyntax cfg.CommentSyntax, config *cfg.Config) (*string, int) {
+	trimmedLine := strings.TrimLeftFunc(line, unicode.IsSpace)
+
+	commentTokens := slices.Clone(commentSyntax.SingleLineCommentTokens)
+	for _, tokens := range commentSyntax.MultilineCommentTokens {
+		commentTokens = append(commentTokens, tokens.Start)
+	}
+	commentTokens = append(commentTokens, commentSyntax.DocstringTokens...)
+	for _, token := range commentTokens {
+		if !strings.HasPrefix(trimmedLine, token) {
+			continue
+		}
+		text := strings.TrimLeftFunc(strings.TrimPrefix(trimmedLine, token), unicode.IsSpace)
+		if directive := getDirective(text, config); directive != nil {
+			return directive, len(line) - len(text) + 1
 		}
+	}
+	return nil, 0
+}
 
-		if !unicode.IsSpace(r) {
-			return true
+// checkUnknownDirectivesInContent reports directives placed inside comment blocks
+func checkUnknownDirectivesInContent(parsingResult *parsers.ParsingResult, sourceMap *markdownSourceMap, config *cfg.Config, diagnosticsCollector *diagnostics.Collector) {
+	for i, contentLine := range strings.Split(string(parsingResult.Content), "\n") {
+		text := strings.TrimLeftFunc(contentLine, unicode.IsSpace)
+		directive := getDirective(text, config)
+		if directive == nil {
+			continue
 		}
+		column := sourceMap.lineColumns[i] + len(contentLine) - len(text)
+		if *directive == getIncludeDirective(config) {
+			// Is handled by includeBlockParser
+			continue
+		}
+		if isCodeDirective(*directive, config) {
+			diagnosticsCollector.Warnf(sourceMap.firstLine+i, column, diagnostics.CodeMisplacedDirective, "directive %q must be placed outside of comment blocks", *directive)
+		} else {
+			diagnosticsCollector.Warnf(sourceMap.firstLine+i, column, diagnostics.CodeUnknownDirective, "unknown directive %q", *directive)
+		}
+	}
+}
 
-		i += sz
+// getLineNumbers returns numbers of the lines from the first to the last one
+func getLineNumbers(firstLine, lastLine int) []int {
+	lineNumbers := make([]int, 0, lastLine-firstLine+1)
+	for line := firstLine; line <= lastLine; line++ {
+		lineNumbers = append(lineNumbers, line)
 	}
-	return false
+	return lineNumbers
 }
 
-func parseBlocks(scanner *bufio.Scanner, commentParsers []parsers.CommentParser, absPathToProjectRoot, absPathToCurrentFile, absPathToResultDir, absPathToResultFile string, pathsIgnorer pathsignorer.PathsIgnorer) ([]block, error) {
+func parseBlocks(scanner *parsers.LinesScanner, language cfg.Language, config *cfg.Config, absPathToProjectRoot, absPathToCurrentFile, absPathToResultDir, absPathToResultFile string, pathsIgnorer pathsignorer.PathsIgnorer, diagnosticsCollector *diagnostics.Collector, includedFiles *includedFiles) ([]block, error) {
+	commentParsers := buildCommentParsersByLanguage(config, language)
+	commentSyntax := config.GetCommentSyntax(language)
+	// Markers inside strings (e.g. "// @docsncode" line of a raw string) must not start comment blocks
+	tracker := lexer.NewTracker(language, config)
+
 	var current_code_block_content []byte
+	var currentCodeBlockLineNumbers []int
 	blocks := make([]block, 0)
+	// Position of the hide start marker, 0 outside of hidden regions
+	hiddenRegionStartLine, hiddenRegionStartColumn := 0, 0
+	// Lines of the comment blocks with ids, the ids must be unique in the page
+	blockIDToLine := make(map[string]int)
 
 	for scanner.Scan() {
 		line := scanner.Text()
+		startLineNumber := scanner.LineNumber()
 
 		anyParserTriggered := false
+		isInsideToken := tracker.IsInsideToken()
+		isInsideHiddenRegion := hiddenRegionStartLine != 0
 		for _, parser := range commentParsers {
-			if !parser.Trigger(line) {
+			// Comment blocks inside hidden regions are hidden with the code
+			if isInsideToken || isInsideHiddenRegion || !parser.Trigger(line) {
 				continue
 			}
 			// TODO: add parser name to log
-			fmt.Println("Some parser triggered")
+			log.Println("Some parser triggered")
 			anyParserTriggered = true
 
-			if isCodeBlockContentAllowed(current_code_block_content) {
-				log.Println("Append current code block")
-				blocks = append(blocks, block{
-					Type:            code,
-					Content:         string(current_code_block_content),
-					IndentSpacesCnt: 0,
-				})
-			}
-			current_code_block_content = nil
-
+			// Lines of the broken comment block are shown as code
+			scanner.Mark()
 			parsingResult, err := parser.Parse(line, scanner)
 			if err != nil {
 				log.Printf("error on parsing: %s", err)
+				scanner.Rewind()
+				column := len(line) - len(strings.TrimLeftFunc(line, unicode.IsSpace)) + 1
+				if errors.Is(err, parsers.ErrCommentBlockEndNotFound) {
+					diagnosticsCollector.Errorf(startLineNumber, column, diagnostics.CodeUnterminatedCommentBlock, "comment block is not terminated with %q, its lines are shown as code", config.CommentBlockEndToken)
+				} else {
+					diagnosticsCollector.Errorf(startLineNumber, column, diagnostics.CodeCommentBlockParsing, "error on parsing comment block: %s", err)
+				}
 				anyParserTriggered = false
-				continue
+				break
 			}
+			scanner.Unmark()
+			for _, diagnostic := range parsingResult.Diagnostics {
+				diagnosticsCollector.Add(diagnostic)
+			}
+
+			sourceMap := &markdownSourceMap{firstLine: startLineNumber + 1, lineColumns: parsingResult.ContentLineColumns}
+			checkUnknownDirectivesInContent(parsingResult, sourceMap, config, diagnosticsCollector)
 
-			htmlContent, err := convertMarkdownToHTML(parsingResult.Content, absPathToProjectRoot, absPathToCurrentFile, absPathToResultDir, absPathToResultFile, pathsIgnorer)
+			htmlContent, err := convertMarkdownToHTML(parsingResult.Content, sourceMap, config, absPathToProjectRoot, absPathToCurrentFile, absPathToResultDir, absPathToResultFile, pathsIgnorer, diagnosticsCollector, includedFiles)
 			if err != nil {
 				return nil, err
 			}
 
+			if parsingResult.Options.IsHidden {
+				// The code around the hidden block stays in one code block
+				log.Println("Comment block is hidden")
+				break
+			}
+
+			blockID := parsingResult.Options.ID
+			if line, isPresent := blockIDToLine[blockID]; isPresent {
+				diagnosticsCollector.Warnf(startLineNumber, parsingResult.Options.IDColumn, diagnostics.CodeInvalidBlockOption, "id %q is already used by the block at line %d", blockID, line)
+				blockID = ""
+			} else if blockID != "" {
+				blockIDToLine[blockID] = startLineNumber
+			}
+
+			log.Println("Append current code block")
+			blocks = appendCodeBlock(blocks, current_code_block_content, currentCodeBlockLineNumbers, false)
+			current_code_block_content = nil
+			currentCodeBlockLineNumbers = nil
+
+			headings, text := extractSearchableText(parsingResult.Content)
 			blocks = append(blocks, block{
 				Type:            comment,
-				Content:         string(htmlContent),
+				Content:         template.HTML(htmlContent),
 				IndentSpacesCnt: parsingResult.BlockIndent,
+				Headings:        headings,
+				Text:            text,
+				ID:              blockID,
+				Class:           strings.Join(parsingResult.Options.Classes, " "),
+				IsCollapsed:     parsingResult.Options.IsCollapsed,
+				LineNumbers:     getLineNumbers(startLineNumber, scanner.LineNumber()),
 			})
+			// The start line is already consumed, other parsers shouldn't look at it
+			break
 		}
 
 		if anyParserTriggered {
 			continue
 		}
+		var directive *string
+		var directiveColumn int
+		if !isInsideToken {
+			directive, directiveColumn = findDirectiveInCode(line, commentSyntax, config)
+		}
+		tracker.Feed(line)
+
+		// Lines with hide markers are not shown
+		switch {
+		case directive == nil:
+		case *directive == getHideStartDirective(config):
+			if isInsideHiddenRegion {
+				diagnosticsCollector.Warnf(startLineNumber, directiveColumn, diagnostics.CodeInvalidHiddenRegion, "hidden regions can't be nested, %q is already at line %d", *directive, hiddenRegionStartLine)
+				continue
+			}
+			log.Println("Append current code block before hidden region")
+			blocks = appendCodeBlock(blocks, current_code_block_content, currentCodeBlockLineNumbers, false)
+			current_code_block_content = nil
+			currentCodeBlockLineNumbers = nil
+			hiddenRegionStartLine, hiddenRegionStartColumn = startLineNumber, directiveColumn
+			continue
+		case *directive == getHideEndDirective(config):
+			if !isInsideHiddenRegion {
+				diagnosticsCollector.Warnf(startLineNumber, directiveColumn, diagnostics.CodeInvalidHiddenRegion, "%q has no matching %q", *directive, getHideStartDirective(config))
+				continue
+			}
+			log.Println("Append hidden region")
+			blocks = appendCodeBlock(blocks, current_code_block_content, currentCodeBlockLineNumbers, true)
+			current_code_block_content = nil
+			currentCodeBlockLineNumbers = nil
+			hiddenRegionStartLine, hiddenRegionStartColumn = 0, 0
+			continue
+		case *directive == getIncludeDirective(config):
+			diagnosticsCollector.Warnf(startLineNumber, directiveColumn, diagnostics.CodeMisplacedDirective, "directive %q must be placed inside comment blocks", *directive)
+		default:
+			diagnosticsCollector.Warnf(startLineNumber, directiveColumn, diagnostics.CodeUnknownDirective, "unknown directive %q", *directive)
+		}
 
 		if current_code_block_content == nil {
 			current_code_block_content = []byte(line)
@@ -190,17 +374,21 @@ func parseBlocks(scanner *bufio.Scanner, commentParsers []parsers.CommentParser,
 			current_code_block_content = append(current_code_block_content, '\n')
 			current_code_block_content = append(current_code_block_content, line...)
 		}
+		currentCodeBlockLineNumbers = append(currentCodeBlockLineNumbers, startLineNumber)
 	}
 
-	if isCodeBlockContentAllowed(current_code_block_content) {
-		log.Println("Append final code block")
-		blocks = append(blocks, block{
-			Type:            code,
-			Content:         string(current_code_block_content),
-			IndentSpacesCnt: 0,
-		})
+	if hiddenRegionStartLine != 0 {
+		// The rest of the file is hidden
+		diagnosticsCollector.Errorf(hiddenRegionStartLine, hiddenRegionStartColumn, diagnostics.CodeInvalidHiddenRegion, "hidden region is not terminated with %q", getHideEndDirective(config))
 	}
+	log.Println("Append final code block")
+	blocks = appendCodeBlock(blocks, current_code_block_content, currentCodeBlockLineNumbers, hiddenRegionStartLine != 0)
 	current_code_block_content = nil
+	currentCodeBlockLineNumbers = nil
+
+	if config.HideLicense {
+		blocks = hideLicenseComment(blocks, language, config)
+	}
 
 	if err := scanner.Err(); err != nil {
 		return nil, fmt.Errorf("error on scanning file: %w", err)
@@ -208,22 +396,90 @@ func parseBlocks(scanner *bufio.Scanner, commentParsers []parsers.CommentParser,
 	return blocks, nil
 }
 
-func BuildHTML(file *os.File, language cfg.Language, absPathToProjectRoot, absPathToCurrentFile, absPathToResultDir, absPathToResultFile string, pathsIgnorer pathsignorer.PathsIgnorer) ([]byte, error) {
-	scanner := bufio.NewScanner(file)
-	commentParsers := buildCommentParsersByLanguage(language)
+func newDiagnosticsCollector(absPathToProjectRoot, absPathToCurrentFile string) (*diagnostics.Collector, error) {
+	relPath, err := filepath.Rel(absPathToProjectRoot, absPathToCurrentFile)
+	if err != nil {
+		return nil, fmt.Errorf("error on building relative path to %s: %w", absPathToCurrentFile, err)
+	}
+	return diagnostics.NewCollector(models.RelPathFromProjectRoot(relPath)), nil
+}
 
-	blocks, err := parseBlocks(scanner, commentParsers, absPathToProjectRoot, absPathToCurrentFile, absPathToResultDir, absPathToResultFile, pathsIgnorer)
+// BuildHTML returns the page, the searchable content of the file, the problems found in its comment blocks
+// and the project files included into the page
+func BuildHTML(file *os.File, language cfg.Language, config *cfg.Config, absPathToProjectRoot, absPathToCurrentFile, absPathToResultDir, absPathToResultFile string, pathsIgnorer pathsignorer.PathsIgnorer, navigationTree *NavigationTree) ([]byte, *search.Document, []diagnostics.Diagnostic, []models.RelPathFromProjectRoot, error) {
+	diagnosticsCollector, err := newDiagnosticsCollector(absPathToProjectRoot, absPathToCurrentFile)
 	if err != nil {
-		return nil, fmt.Errorf("error on parsing blocks: %w", err)
+		return nil, nil, nil, nil, err
+	}
+
+	source, err := readSource(file, absPathToCurrentFile, config)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	includedFiles := &includedFiles{}
+	blocks, err := parseBlocks(parsers.NewLinesScanner(strings.NewReader(source)), language, config, absPathToProjectRoot, absPathToCurrentFile, absPathToResultDir, absPathToResultFile, pathsIgnorer, diagnosticsCollector, includedFiles)
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("error on parsing blocks: %w", err)
+	}
+
+	relPathToResultFile, err := filepath.Rel(absPathToResultDir, absPathToResultFile)
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("error on building relative path to %s: %w", absPathToResultFile, err)
 	}
+	searchDocument := buildSearchDocument(blocks, string(diagnosticsCollector.Path()), relPathToResultFile)
 
-	escapeHTMLInCodeBlocks(blocks)
+	collapseCodeBlocks(blocks, language, config)
+
+	var highlightStyleCSS string
+	if config.ServerSideHighlighting {
+		highlightStyleCSS, err = highlight.GetStyleCSS(config.HighlightStyle)
+		if err != nil {
+			return nil, nil, nil, nil, fmt.Errorf("error on getting highlight style: %w", err)
+		}
+		highlightCodeBlocks(blocks, language, config)
+	} else {
+		escapeHTMLInCodeBlocks(blocks, language, config)
+	}
+
+	var sections []section
+	if config.Layout == cfg.LayoutSideBySide {
+		sections = buildSections(blocks)
+	}
 
 	resultBuf := bytes.NewBuffer([]byte{})
-	err = htmlTemplate.Execute(resultBuf, htmlTemplateData{Blocks: blocks, HighlightJsLanguageName: cfg.GetHighlightJSLanguageName(language)})
+	err = getTheme(config).Execute(resultBuf, theme.PAGE_TEMPLATE_NAME, htmlTemplateData{
+		templateContext:   newTemplateContext(config, absPathToResultDir, absPathToResultFile),
+		Path:              filepath.ToSlash(string(diagnosticsCollector.Path())),
+		Language:          string(language),
+		Blocks:            blocks,
+		Sections:          sections,
+		TabSize:           config.TabSize,
+		Navigation:        navigationTree.buildData(absPathToResultDir, absPathToResultFile),
+		Assets:            getAssetURLs(config, absPathToResultDir, absPathToResultFile),
+		HighlightStyleCSS: template.CSS(highlightStyleCSS),
+	})
 	if err != nil {
-		return nil, fmt.Errorf("error on filling HTML template: %w", err)
+		return nil, nil, nil, nil, fmt.Errorf("error on filling HTML template: %w", err)
 	}
 
-	return resultBuf.Bytes(), nil
+	return resultBuf.Bytes(), &searchDocument, diagnosticsCollector.Diagnostics(), includedFiles.paths, nil
+}
+
+// CheckFile parses the file like BuildHTML does, but returns found problems instead of HTML
+func CheckFile(file *os.File, language cfg.Language, config *cfg.Config, absPathToProjectRoot, absPathToCurrentFile string, pathsIgnorer pathsignorer.PathsIgnorer) ([]diagnostics.Diagnostic, error) {
+	diagnosticsCollector, err := newDiagnosticsCollector(absPathToProjectRoot, absPathToCurrentFile)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := readSource(file, absPathToCurrentFile, config)
+	if err != nil {
+		return nil, err
+	}
+	// Nothing is written, so links are resolved as if the result was placed near the source file
+	_, err = parseBlocks(parsers.NewLinesScanner(strings.NewReader(source)), language, config, absPathToProjectRoot, absPathToCurrentFile, absPathToProjectRoot, absPathToCurrentFile+".html", pathsIgnorer, diagnosticsCollector, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error on parsing blocks: %w", err)
+	}
+	return diagnosticsCollector.Diagnostics(), nil
 }
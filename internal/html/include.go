@@ -0,0 +1,318 @@
+package html
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+
+	gast "github.com/yuin/goldmark/ast"
+	gparser "github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+
+	"docsncode/internal/cfg"
+	"docsncode/internal/diagnostics"
+	"docsncode/internal/models"
+)
+
+// Line ranges of the include directive: #L10 or #L10-L30
+var lineRangeRegexp = regexp.MustCompile(`^L(\d+)(?:-L(\d+))?$`)
+
+// Go symbols of the include directive: #FuncName or #Type.Method
+var goSymbolRegexp = regexp.MustCompile(`^[A-Za-z_]\w*(?:\.[A-Za-z_]\w*)?$`)
+
+var KindInclude = gast.NewNodeKind("DocsncodeInclude")
+
+// includeNode is an excerpt of another file, which is placed by the include directive
+type includeNode struct {
+	gast.BaseBlock
+	Code     string
+	Language *cfg.Language
+	// Link to the full rendered page of the file
+	Destination string
+	Title       string
+}
+
+func (n *includeNode) Kind() gast.NodeKind {
+	return KindInclude
+}
+
+func (n *includeNode) Dump(source []byte, level int) {
+	gast.DumpHelper(n, source, level, map[string]string{"Title": n.Title}, nil)
+}
+
+// includedFiles collects the project files that are included into one page, so the page can be rebuilt
+// when any of them is changed. Nil collector ignores the files.
+type includedFiles struct {
+	paths []models.RelPathFromProjectRoot
+}
+
+func (f *includedFiles) add(path models.RelPathFromProjectRoot) {
+	if f == nil || slices.Contains(f.paths, path) {
+		return
+	}
+	f.paths = append(f.paths, path)
+}
+
+func getIncludeDirective(config *cfg.Config) string {
+	return config.CommentBlockStartToken + "-include"
+}
+
+// includeBlockParser parses "@docsncode-include path/to/file.go#L10-L30" lines of comment blocks.
+// Paths are resolved like the links, relative to the current file.
+type includeBlockParser struct {
+	linksResolver *linksResolverTransformer
+}
+
+func (p *includeBlockParser) Trigger() []byte {
+	return []byte{p.linksResolver.config.CommentBlockStartToken[0]}
+}
+
+func (p *includeBlockParser) addDiagnostic(source []byte, offset int, format string, args ...any) {
+	if p.linksResolver.diagnosticsCollector == nil {
+		return
+	}
+	line, column := p.linksResolver.sourceMap.position(source, offset)
+	p.linksResolver.diagnosticsCollector.Warnf(line, column, diagnostics.CodeInvalidInclude, format, args...)
+}
+
+func (p *includeBlockParser) Open(parent gast.Node, reader text.Reader, pc gparser.Context) (gast.Node, gparser.State) {
+	line, segment := reader.PeekLine()
+	trimmedLine := strings.TrimLeft(string(line), " \t")
+	offset := segment.Start + len(line) - len(trimmedLine)
+
+	fields := strings.Fields(trimmedLine)
+	directive := getIncludeDirective(p.linksResolver.config)
+	if len(fields) == 0 || fields[0] != directive {
+		return nil, gparser.NoChildren
+	}
+	if len(fields) != 2 {
+		p.addDiagnostic(reader.Source(), offset, "directive %q must have one target like %s path/to/file.go#L10-L30", directive, directive)
+		return nil, gparser.NoChildren
+	}
+
+	target := fields[1]
+	targetOffset := offset + strings.Index(trimmedLine, target)
+	node, err := p.buildIncludeNode(target)
+	if err != nil {
+		// The directive stays in the text, so the gap is visible
+		log.Printf("error on including %s: %s", target, err)
+		p.addDiagnostic(reader.Source(), targetOffset, "can't include %s: %s", target, err)
+		return nil, gparser.NoChildren
+	}
+
+	reader.Advance(segment.Len() - 1)
+	return node, gparser.NoChildren
+}
+
+func (p *includeBlockParser) buildIncludeNode(target string) (*includeNode, error) {
+	path, fragment, _ := strings.Cut(target, "#")
+	absPath := p.linksResolver.getAbsPath(path)
+	if absPath == nil {
+		return nil, fmt.Errorf("target must be a path to a file")
+	}
+	relPath, err := p.getRelPathToIncludedFile(*absPath)
+	if err != nil {
+		return nil, err
+	}
+	// Missing files are recorded too, so the page is rebuilt when they are created
+	p.linksResolver.includedFiles.add(relPath)
+
+	file, err := os.Open(*absPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("file is not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error on opening file: %w", err)
+	}
+	defer file.Close()
+	source, err := readSource(file, *absPath, p.linksResolver.config)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := splitLines(source)
+	firstLine, lastLine := 1, len(lines)
+	if fragment != "" {
+		firstLine, lastLine, err = getIncludedLines(*absPath, []byte(source), fragment, len(lines))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	destination := path
+	if fragment != "" {
+		destination = fmt.Sprintf("%s#L%d-L%d", path, firstLine, lastLine)
+	}
+	return &includeNode{
+		Code:        strings.Join(lines[firstLine-1:lastLine], "\n"),
+		Language:    p.linksResolver.config.GetLanguageNameIfSupported(filepath.Ext(*absPath)),
+		Destination: string(p.linksResolver.getUpdatedDestination([]byte(destination))),
+		Title:       target,
+	}, nil
+}
+
+// getRelPathToIncludedFile returns the path from the project root. Files outside of the project
+// and ignored files can't be included, because they don't belong to the documentation.
+func (p *includeBlockParser) getRelPathToIncludedFile(absPath string) (models.RelPathFromProjectRoot, error) {
+	relPath, err := filepath.Rel(p.linksResolver.absPathToProjectRoot, absPath)
+	if err != nil || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("file is outside of the project root")
+	}
+	// Ignored dirs hide everything inside them
+	for parent := relPath; parent != "."; parent = filepath.Dir(parent) {
+		if p.linksResolver.pathsIgnorer.ShouldIgnore(models.RelPathFromProjectRoot(parent)) {
+			return "", fmt.Errorf("file is ignored by .docsncodeignore")
+		}
+	}
+	return models.RelPathFromProjectRoot(relPath), nil
+}
+
+// getIncludedLines returns the range of the lines by the fragment of the include target
+func getIncludedLines(absPath string, content []byte, fragment string, linesCnt int) (int, int, error) {
+	if match := lineRangeRegexp.FindStringSubmatch(fragment); match != nil {
+		firstLine, _ := strconv.Atoi(match[1])
+		lastLine := firstLine
+		if match[2] != "" {
+			lastLine, _ = strconv.Atoi(match[2])
+		}
+		if firstLine < 1 || lastLine < firstLine || lastLine > linesCnt {
+			return 0, 0, fmt.Errorf("lines L%d-L%d are out of the file with %d lines", firstLine, lastLine, linesCnt)
+		}
+		return firstLine, lastLine, nil
+	}
+
+	if !goSymbolRegexp.MatchString(fragment) {
+		return 0, 0, fmt.Errorf("fragment %q must be a line range like L10-L30 or a Go symbol like FuncName", fragment)
+	}
+	if filepath.Ext(absPath) != ".go" {
+		return 0, 0, fmt.Errorf("symbols can be included only from Go files, use a line range like L10-L30")
+	}
+	return findGoSymbolLines(absPath, content, fragment)
+}
+
+func getReceiverTypeName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return getReceiverTypeName(e.X)
+	case *ast.IndexExpr:
+		return getReceiverTypeName(e.X)
+	case *ast.IndexListExpr:
+		return getReceiverTypeName(e.X)
+	case *ast.Ident:
+		return e.Name
+	}
+	return ""
+}
+
+// findGoSymbolLines returns the lines of the top-level declaration with its doc comment.
+// Methods are named like Type.Method.
+func findGoSymbolLines(absPath string, content []byte, symbol string) (int, int, error) {
+	fileSet := token.NewFileSet()
+	file, err := parser.ParseFile(fileSet, absPath, content, parser.ParseComments)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error on parsing Go file: %w", err)
+	}
+
+	typeName, name, isMethod := strings.Cut(symbol, ".")
+	if !isMethod {
+		name = symbol
+	}
+	getLines := func(doc *ast.CommentGroup, node ast.Node) (int, int, error) {
+		start := node.Pos()
+		if doc != nil {
+			start = doc.Pos()
+		}
+		return fileSet.Position(start).Line, fileSet.Position(node.End()).Line, nil
+	}
+
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Name.Name != name || (d.Recv != nil) != isMethod {
+				continue
+			}
+			if isMethod && (len(d.Recv.List) == 0 || getReceiverTypeName(d.Recv.List[0].Type) != typeName) {
+				continue
+			}
+			return getLines(d.Doc, d)
+		case *ast.GenDecl:
+			if isMethod {
+				continue
+			}
+			for _, spec := range d.Specs {
+				var names []*ast.Ident
+				var doc *ast.CommentGroup
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					names, doc = []*ast.Ident{s.Name}, s.Doc
+				case *ast.ValueSpec:
+					names, doc = s.Names, s.Doc
+				}
+				for _, ident := range names {
+					if ident.Name != name {
+						continue
+					}
+					// Declarations of one spec are included with their keyword
+					if len(d.Specs) == 1 {
+						return getLines(d.Doc, d)
+					}
+					return getLines(doc, spec)
+				}
+			}
+		}
+	}
+	return 0, 0, fmt.Errorf("symbol %s is not found", symbol)
+}
+
+func (p *includeBlockParser) Continue(node gast.Node, reader text.Reader, pc gparser.Context) gparser.State {
+	return gparser.Close
+}
+
+func (p *includeBlockParser) Close(node gast.Node, reader text.Reader, pc gparser.Context) {
+}
+
+func (p *includeBlockParser) CanInterruptParagraph() bool {
+	return true
+}
+
+func (p *includeBlockParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+// includeRenderer renders the excerpt like fenced code blocks and the link to the full page of the file
+type includeRenderer struct {
+	config *cfg.Config
+}
+
+func (r *includeRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindInclude, r.renderInclude)
+}
+
+func (r *includeRenderer) renderInclude(w util.BufWriter, source []byte, node gast.Node, entering bool) (gast.WalkStatus, error) {
+	if !entering {
+		return gast.WalkContinue, nil
+	}
+	include := node.(*includeNode)
+
+	var languageName string
+	if include.Language != nil {
+		languageName = string(*include.Language)
+	}
+	_, _ = w.WriteString(`<div class="docsncode-include">`)
+	writeCommentCode(w, include.Code, languageName, r.config)
+	_, _ = w.WriteString(`<a class="docsncode-include-link" href="` + template.HTMLEscapeString(include.Destination) + `">` + template.HTMLEscapeString(include.Title) + "</a></div>\n")
+	return gast.WalkSkipChildren, nil
+}
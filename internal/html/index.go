@@ -0,0 +1,105 @@
+package html
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"docsncode/internal/cfg"
+	"docsncode/internal/charset"
+	"docsncode/internal/models"
+	"docsncode/internal/paths"
+	"docsncode/internal/pathsignorer"
+	"docsncode/internal/theme"
+)
+
+type indexEntry struct {
+	Name string
+	Href string
+}
+
+// indexTemplateData is passed to the index template of the theme (see docs/themes.md)
+type indexTemplateData struct {
+	templateContext
+	Title string
+	// Empty for the landing page
+	ParentHref string
+	// Rendered README.md of the directory
+	Intro template.HTML
+	Dirs  []indexEntry
+	Files []indexEntry
+	// Nil if the sidebar is not needed
+	Navigation *navigationData
+}
+
+func buildIntro(config *cfg.Config, absPathToProjectRoot, absPathToSourceDir, absPathToResultDir, absPathToResultFile string, pathsIgnorer pathsignorer.PathsIgnorer) (template.HTML, error) {
+	absPathToReadme := filepath.Join(absPathToSourceDir, paths.README_FILE_NAME)
+	relPathToReadme, err := filepath.Rel(absPathToProjectRoot, absPathToReadme)
+	if err != nil {
+		return "", fmt.Errorf("error on building relative path to %s: %w", absPathToReadme, err)
+	}
+	if pathsIgnorer.ShouldIgnore(models.RelPathFromProjectRoot(relPathToReadme)) {
+		log.Printf("paths ignorer said to ignore %s", relPathToReadme)
+		return "", nil
+	}
+
+	md, err := os.ReadFile(absPathToReadme)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("couldn't read %s: %w", absPathToReadme, err)
+	}
+
+	// READMEs can have BOMs and other encodings like the source files
+	readme, _ := charset.Decode(md, config.GetEncoding(filepath.Ext(absPathToReadme)))
+	intro, err := convertMarkdownToHTML([]byte(readme), nil, config, absPathToProjectRoot, absPathToReadme, absPathToResultDir, absPathToResultFile, pathsIgnorer, nil, nil)
+	if err != nil {
+		return "", err
+	}
+	return template.HTML(intro), nil
+}
+
+// BuildIndexHTML builds the index page of the result directory.
+// resultFileNames are names of the result files (e.g. main.go.html) placed directly in the directory.
+func BuildIndexHTML(config *cfg.Config, absPathToProjectRoot, absPathToSourceDir, absPathToResultDir, absPathToResultFile string, subdirNames, resultFileNames []string, pathsIgnorer pathsignorer.PathsIgnorer, navigationTree *NavigationTree) ([]byte, error) {
+	relPathToDir, err := filepath.Rel(absPathToProjectRoot, absPathToSourceDir)
+	if err != nil {
+		return nil, fmt.Errorf("error on building relative path to %s: %w", absPathToSourceDir, err)
+	}
+
+	data := indexTemplateData{
+		templateContext: newTemplateContext(config, absPathToResultDir, absPathToResultFile),
+		Title:           filepath.ToSlash(relPathToDir),
+		Navigation:      navigationTree.buildData(absPathToResultDir, absPathToResultFile),
+	}
+	if relPathToDir == "." {
+		data.Title = filepath.Base(absPathToProjectRoot)
+	} else {
+		data.ParentHref = "../" + paths.INDEX_PAGE_FILE_NAME
+	}
+
+	data.Intro, err = buildIntro(config, absPathToProjectRoot, absPathToSourceDir, absPathToResultDir, absPathToResultFile, pathsIgnorer)
+	if err != nil {
+		return nil, fmt.Errorf("error on building intro: %w", err)
+	}
+
+	for _, name := range subdirNames {
+		data.Dirs = append(data.Dirs, indexEntry{Name: name, Href: url.PathEscape(name) + "/" + paths.INDEX_PAGE_FILE_NAME})
+	}
+	for _, name := range resultFileNames {
+		data.Files = append(data.Files, indexEntry{Name: strings.TrimSuffix(name, ".html"), Href: url.PathEscape(name)})
+	}
+
+	resultBuf := bytes.NewBuffer([]byte{})
+	err = getTheme(config).Execute(resultBuf, theme.INDEX_TEMPLATE_NAME, data)
+	if err != nil {
+		return nil, fmt.Errorf("error on filling index template: %w", err)
+	}
+	return resultBuf.Bytes(), nil
+}
@@ -0,0 +1,24 @@
+package html
+
+// section is a row of the side-by-side layout: a comment block and the code blocks that follow it
+type section struct {
+	// Is nil for the code before the first comment block
+	Comment *block
+	Code    []block
+}
+
+func buildSections(blocks []block) []section {
+	var sections []section
+	for i := range blocks {
+		if blocks[i].Type == comment || len(sections) == 0 {
+			sections = append(sections, section{})
+		}
+		current := &sections[len(sections)-1]
+		if blocks[i].Type == comment {
+			current.Comment = &blocks[i]
+		} else {
+			current.Code = append(current.Code, blocks[i])
+		}
+	}
+	return sections
+}
@@ -3,24 +3,34 @@ package html
 import (
 	"log"
 	"net/url"
+	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/yuin/goldmark/ast"
 	"github.com/yuin/goldmark/parser"
 	"github.com/yuin/goldmark/text"
 
 	"docsncode/internal/cfg"
+	"docsncode/internal/diagnostics"
 	"docsncode/internal/models"
 	"docsncode/internal/paths"
 	"docsncode/internal/pathsignorer"
 )
 
 type linksResolverTransformer struct {
+	config               *cfg.Config
 	absPathToProjectRoot string
 	absPathToCurrentFile string
 	absPathToResultDir   string
 	absPathToResultFile  string
 	pathsIgnorer         pathsignorer.PathsIgnorer
+
+	// Are used to report links to missing files
+	sourceMap            *markdownSourceMap
+	diagnosticsCollector *diagnostics.Collector
+	// Files of the include directives
+	includedFiles *includedFiles
 }
 
 func isURL(str string) bool {
@@ -49,23 +59,69 @@ func (t *linksResolverTransformer) willThereBeResultFileWithSuchPath(path models
 		log.Printf("path=%s is ignored by paths ignorer", path)
 		return false
 	}
-	return cfg.GetLanguageNameIfSupported(filepath.Ext(string(path))) != nil
+	return t.config.GetLanguageNameIfSupported(filepath.Ext(string(path))) != nil
 }
 
-func (t *linksResolverTransformer) getUpdatedPath(path []byte) []byte {
-	pathString := string(path)
-	if isURL(pathString) {
+// getAbsPath returns nil if the destination is not a path (e.g. URL or anchor on the same page)
+func (t *linksResolverTransformer) getAbsPath(destination string) *string {
+	if isURL(destination) {
 		log.Printf("Destination is URL")
-		return path
+		return nil
+	}
+	if destination == "" || strings.HasPrefix(destination, "#") {
+		log.Printf("Destination is anchor on the same page")
+		return nil
 	}
 
-	absPath := pathString
+	absPath := destination
 	if !filepath.IsAbs(absPath) {
 		absPath = filepath.Join(filepath.Dir(t.absPathToCurrentFile), absPath)
 	} else {
 		// TODO: make it a warning
 		log.Println("found link with absolute path. It probably won't work on a different host")
 	}
+	return &absPath
+}
+
+// checkDestinationExists reports the destination if its file doesn't exist.
+// The query (e.g. ?plain=1) and the fragment are not a part of the file path, and the path may be escaped (e.g. my%20file.md).
+func (t *linksResolverTransformer) checkDestinationExists(node ast.Node, destination []byte, source []byte) {
+	path, _, _ := strings.Cut(string(destination), "#")
+	path, _, _ = strings.Cut(path, "?")
+	if unescapedPath, err := url.PathUnescape(path); err == nil {
+		path = unescapedPath
+	}
+	absPath := t.getAbsPath(path)
+	if absPath == nil || t.diagnosticsCollector == nil {
+		return
+	}
+	if _, err := os.Stat(*absPath); err == nil {
+		return
+	}
+
+	kind := "link"
+	if node.Kind() == ast.KindImage {
+		kind = "image"
+	}
+	line, column := t.sourceMap.position(source, getNodeOffset(node))
+	t.diagnosticsCollector.Warnf(line, column, diagnostics.CodeMissingLinkTarget, "%s points at missing file %s", kind, destination)
+}
+
+// getUpdatedDestination updates the path of the destination and keeps its fragment (e.g. #L120-L140)
+func (t *linksResolverTransformer) getUpdatedDestination(destination []byte) []byte {
+	path, fragment, hasFragment := strings.Cut(string(destination), "#")
+	if !hasFragment || t.getAbsPath(path) == nil {
+		return t.getUpdatedPath(destination)
+	}
+	return []byte(string(t.getUpdatedPath([]byte(path))) + "#" + fragment)
+}
+
+func (t *linksResolverTransformer) getUpdatedPath(path []byte) []byte {
+	absPathPtr := t.getAbsPath(string(path))
+	if absPathPtr == nil {
+		return path
+	}
+	absPath := *absPathPtr
 
 	log.Printf("absPath=%s", absPath)
 
@@ -102,7 +158,12 @@ func (t *linksResolverTransformer) getUpdatedPath(path []byte) []byte {
 		return []byte(relResultPath)
 	}
 
-	relPath, err := filepath.Rel(t.absPathToResultDir, absPath)
+	if t.config.ProjectFilesURLPath != "" {
+		// The project files are served next to the result, so the link must stay inside the result dir
+		absPath = filepath.Join(t.absPathToResultDir, filepath.FromSlash(t.config.ProjectFilesURLPath), relPathFromProjectRoot)
+	}
+
+	relPath, err := filepath.Rel(filepath.Dir(t.absPathToResultFile), absPath)
 	if err != nil {
 		log.Printf("error on getting relative path for %s, %s: %s", t.absPathToResultFile, absPath, err)
 		return path
@@ -119,7 +180,8 @@ func (t *linksResolverTransformer) Transform(node *ast.Document, reader text.Rea
 		if node.Kind() == ast.KindImage {
 			img := node.(*ast.Image)
 			log.Printf("Found image with destination=%s", img.Destination)
-			img.Destination = t.getUpdatedPath(img.Destination)
+			t.checkDestinationExists(img, img.Destination, reader.Source())
+			img.Destination = t.getUpdatedDestination(img.Destination)
 			log.Printf("Updated destination is %s", img.Destination)
 			return ast.WalkContinue, nil
 		}
@@ -127,7 +189,8 @@ func (t *linksResolverTransformer) Transform(node *ast.Document, reader text.Rea
 		if node.Kind() == ast.KindLink {
 			link := node.(*ast.Link)
 			log.Printf("Found link with destination=%s", link.Destination)
-			link.Destination = t.getUpdatedPath(link.Destination)
+			t.checkDestinationExists(link, link.Destination, reader.Source())
+			link.Destination = t.getUpdatedDestination(link.Destination)
 			log.Printf("Updated destination is %s", link.Destination)
 			return ast.WalkContinue, nil
 		}
@@ -0,0 +1,143 @@
+package html
+
+import (
+	"log"
+	"net/url"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"docsncode/internal/models"
+	"docsncode/internal/paths"
+	"docsncode/internal/search"
+)
+
+// NavigationTree is a tree of all result files. It's shown in the sidebar of every page.
+type NavigationTree struct {
+	projectName string
+	root        *navigationNode
+}
+
+type navigationNode struct {
+	name string
+	// Path to the result file or to the result dir (with slashes)
+	path     string
+	isDir    bool
+	children []*navigationNode
+}
+
+// navigationItem is a node of the tree prepared for the certain page
+type navigationItem struct {
+	Name string
+	// Relative to the current page
+	Href      string
+	IsDir     bool
+	IsCurrent bool
+	// Directories on the way to the current page are expanded
+	IsOpen   bool
+	Children []navigationItem
+}
+
+func (n *navigationNode) getOrCreateDir(name string) *navigationNode {
+	for _, child := range n.children {
+		if child.isDir && child.name == name {
+			return child
+		}
+	}
+	child := &navigationNode{name: name, path: path.Join(n.path, name), isDir: true}
+	n.children = append(n.children, child)
+	return child
+}
+
+func (n *navigationNode) sort() {
+	// Directories go first like on index pages
+	sort.Slice(n.children, func(i, j int) bool {
+		if n.children[i].isDir != n.children[j].isDir {
+			return n.children[i].isDir
+		}
+		return n.children[i].name < n.children[j].name
+	})
+	for _, child := range n.children {
+		child.sort()
+	}
+}
+
+func NewNavigationTree(projectName string, relPathsToResultFiles []models.RelPathFromResultDir) *NavigationTree {
+	root := &navigationNode{path: ".", isDir: true}
+	for _, relPath := range relPathsToResultFiles {
+		parts := strings.Split(filepath.ToSlash(string(relPath)), "/")
+		node := root
+		for _, part := range parts[:len(parts)-1] {
+			node = node.getOrCreateDir(part)
+		}
+		node.children = append(node.children, &navigationNode{name: parts[len(parts)-1], path: path.Join(node.path, parts[len(parts)-1])})
+	}
+	root.sort()
+	return &NavigationTree{projectName: projectName, root: root}
+}
+
+func getRelativeHref(currentPage, target string) string {
+	href, err := filepath.Rel(path.Dir(currentPage), target)
+	if err != nil {
+		log.Printf("error on building relative path from %s to %s: %v", currentPage, target, err)
+		return target
+	}
+	segments := strings.Split(filepath.ToSlash(href), "/")
+	for i := range segments {
+		segments[i] = url.PathEscape(segments[i])
+	}
+	return strings.Join(segments, "/")
+}
+
+func (n *navigationNode) buildItem(currentPage string) navigationItem {
+	item := navigationItem{Name: n.name, IsDir: n.isDir}
+	if !n.isDir {
+		item.Name = strings.TrimSuffix(n.name, ".html")
+		item.Href = getRelativeHref(currentPage, n.path)
+		item.IsCurrent = n.path == currentPage
+		return item
+	}
+
+	indexPage := path.Join(n.path, paths.INDEX_PAGE_FILE_NAME)
+	item.Href = getRelativeHref(currentPage, indexPage)
+	item.IsCurrent = indexPage == currentPage
+	item.IsOpen = n.path == "." || strings.HasPrefix(currentPage, n.path+"/")
+	for _, child := range n.children {
+		item.Children = append(item.Children, child.buildItem(currentPage))
+	}
+	return item
+}
+
+type navigationData struct {
+	ProjectName string
+	// Relative path from the current page to the result dir, empty or ends with a slash
+	RootHref string
+	// Link to the landing page
+	HomeHref        string
+	SearchIndexHref string
+	Items           []navigationItem
+}
+
+// buildData returns the sidebar content for the page with the given path from the result dir.
+// Returns nil if the tree is nil.
+func (t *NavigationTree) buildData(absPathToResultDir, absPathToCurrentPage string) *navigationData {
+	if t == nil {
+		return nil
+	}
+
+	relPathToCurrentPage, err := filepath.Rel(absPathToResultDir, absPathToCurrentPage)
+	if err != nil {
+		log.Printf("error on getting relative path from %s to %s: %s", absPathToResultDir, absPathToCurrentPage, err)
+		return nil
+	}
+	currentPage := filepath.ToSlash(relPathToCurrentPage)
+
+	return &navigationData{
+		ProjectName:     t.projectName,
+		RootHref:        strings.Repeat("../", strings.Count(currentPage, "/")),
+		HomeHref:        getRelativeHref(currentPage, paths.INDEX_PAGE_FILE_NAME),
+		SearchIndexHref: getRelativeHref(currentPage, search.INDEX_JS_FILE_NAME),
+		Items:           t.root.buildItem(currentPage).Children,
+	}
+}
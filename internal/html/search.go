@@ -0,0 +1,90 @@
+package html
+
+import (
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+
+	"docsncode/internal/search"
+)
+
+var identifierRegexp = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]{2,}`)
+
+// extractSearchableText returns headings and plain text of the markdown
+func extractSearchableText(md []byte) ([]string, string) {
+	document := goldmark.DefaultParser().Parse(text.NewReader(md))
+
+	var headings []string
+	var textBuilder strings.Builder
+	ast.Walk(document, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		switch n := node.(type) {
+		case *ast.Heading:
+			var headingBuilder strings.Builder
+			ast.Walk(n, func(child ast.Node, entering bool) (ast.WalkStatus, error) {
+				if textNode, ok := child.(*ast.Text); ok && entering {
+					headingBuilder.Write(textNode.Segment.Value(md))
+				}
+				return ast.WalkContinue, nil
+			})
+			headings = append(headings, headingBuilder.String())
+		case *ast.Text:
+			textBuilder.Write(n.Segment.Value(md))
+			if n.SoftLineBreak() || n.HardLineBreak() {
+				textBuilder.WriteByte(' ')
+			}
+		case *ast.FencedCodeBlock, *ast.CodeBlock:
+			lines := n.Lines()
+			for i := 0; i < lines.Len(); i++ {
+				segment := lines.At(i)
+				textBuilder.Write(segment.Value(md))
+			}
+		}
+
+		if node.Type() == ast.TypeBlock && textBuilder.Len() != 0 {
+			textBuilder.WriteByte(' ')
+		}
+		return ast.WalkContinue, nil
+	})
+
+	return headings, strings.Join(strings.Fields(textBuilder.String()), " ")
+}
+
+// buildSearchDocument must be called before HTML escaping of the code blocks
+func buildSearchDocument(blocks []block, relPathToSourceFile, relPathToResultFile string) search.Document {
+	document := search.Document{
+		Path:  filepath.ToSlash(relPathToResultFile),
+		Title: filepath.ToSlash(relPathToSourceFile),
+	}
+
+	var texts []string
+	identifiers := make(map[string]struct{})
+	for _, b := range blocks {
+		switch b.Type {
+		case comment:
+			document.Headings = append(document.Headings, b.Headings...)
+			if b.Text != "" {
+				texts = append(texts, b.Text)
+			}
+		case code:
+			for _, identifier := range identifierRegexp.FindAllString(string(b.Content), -1) {
+				identifiers[identifier] = struct{}{}
+			}
+		}
+	}
+
+	document.Text = strings.Join(texts, " ")
+	for identifier := range identifiers {
+		document.Identifiers = append(document.Identifiers, identifier)
+	}
+	slices.Sort(document.Identifiers)
+	return document
+}
@@ -0,0 +1,34 @@
+package html
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"docsncode/internal/cfg"
+	"docsncode/internal/charset"
+	"docsncode/internal/parsers"
+)
+
+// readSource reads the file and converts it to UTF-8 according to the encoding set for its extension
+func readSource(file io.Reader, absPathToFile string, config *cfg.Config) (string, error) {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return "", fmt.Errorf("error on reading file %s: %w", absPathToFile, err)
+	}
+	source, encoding := charset.Decode(data, config.GetEncoding(filepath.Ext(absPathToFile)))
+	log.Printf("File %s is read as %s", absPathToFile, encoding)
+	return source, nil
+}
+
+// splitLines splits the source by the same line ends as parsers.LinesScanner
+func splitLines(source string) []string {
+	scanner := parsers.NewLinesScanner(strings.NewReader(source))
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines
+}
@@ -0,0 +1,57 @@
+package html
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// markdownSourceMap maps offsets in the comment block content to positions in the source file
+type markdownSourceMap struct {
+	// Line of the source file with the first line of the content
+	firstLine int
+	// Columns of the source file where content lines start
+	lineColumns []int
+}
+
+func (m *markdownSourceMap) position(content []byte, offset int) (int, int) {
+	offset = min(max(offset, 0), len(content))
+	lineIndex := bytes.Count(content[:offset], []byte{'\n'})
+	lineStart := bytes.LastIndexByte(content[:offset], '\n') + 1
+
+	column := offset - lineStart + 1
+	if lineIndex < len(m.lineColumns) {
+		column += m.lineColumns[lineIndex] - 1
+	}
+	return m.firstLine + lineIndex, column
+}
+
+// getNodeOffset returns approximate offset of the inline node in the content:
+// inline nodes don't store their positions, so the position of their first text is used
+func getNodeOffset(node ast.Node) int {
+	offset := -1
+	ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		if text, ok := n.(*ast.Text); ok {
+			offset = text.Segment.Start
+			return ast.WalkStop, nil
+		}
+		return ast.WalkContinue, nil
+	})
+
+	switch {
+	case offset != -1 && node.Kind() == ast.KindImage:
+		return offset - len("![")
+	case offset != -1:
+		return offset - len("[")
+	}
+
+	for parent := node.Parent(); parent != nil; parent = parent.Parent() {
+		if parent.Type() == ast.TypeBlock && parent.Lines().Len() > 0 {
+			return parent.Lines().At(0).Start
+		}
+	}
+	return 0
+}
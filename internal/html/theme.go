@@ -0,0 +1,44 @@
+package html
+
+import (
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"docsncode/internal/cfg"
+	"docsncode/internal/theme"
+)
+
+// templateContext has the fields that are passed to all page templates
+type templateContext struct {
+	// Relative path from the page to the result dir, e.g. "../../" or "" for the pages at the root
+	SiteRoot string
+	// Relative path from the page to the static files of the theme with the trailing slash.
+	// Empty if the theme has no static files.
+	ThemeHref string
+	BuildTime time.Time
+}
+
+func getTheme(config *cfg.Config) *theme.Theme {
+	if config.Theme == nil {
+		return theme.Default()
+	}
+	return config.Theme
+}
+
+func newTemplateContext(config *cfg.Config, absPathToResultDir, absPathToResultFile string) templateContext {
+	context := templateContext{BuildTime: time.Now()}
+
+	relPathToResultFile, err := filepath.Rel(absPathToResultDir, absPathToResultFile)
+	if err != nil {
+		log.Printf("error on getting relative path from %s to %s: %s", absPathToResultDir, absPathToResultFile, err)
+	} else {
+		context.SiteRoot = strings.Repeat("../", strings.Count(filepath.ToSlash(relPathToResultFile), "/"))
+	}
+
+	if getTheme(config).HasStatic() {
+		context.ThemeHref = context.SiteRoot + theme.STATIC_DIR_NAME + "/"
+	}
+	return context
+}
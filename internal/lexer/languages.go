@@ -0,0 +1,219 @@
+package lexer
+
+import (
+	"strings"
+
+	"docsncode/internal/cfg"
+)
+
+type languageRules struct {
+	keywords map[string]struct{}
+	literals map[string]struct{}
+	types    map[string]struct{}
+	// Multi-character delimiters must go before the single-character ones (e.g. """ before ")
+	stringDelimiters []stringDelimiter
+	// Keywords of some languages (e.g. Ada) are case-insensitive
+	isCaseInsensitive bool
+	// Nothing is highlighted, even comments
+	isPlainText bool
+	// Heredocs start with << (e.g. <<EOF in Bash or <<<EOT in PHP) and end with the line containing the identifier
+	hasHeredocs bool
+}
+
+type stringDelimiter struct {
+	start, end string
+	// Raw strings don't have escape sequences
+	isRaw bool
+}
+
+func words(s string) map[string]struct{} {
+	result := make(map[string]struct{})
+	for _, word := range strings.Fields(s) {
+		result[word] = struct{}{}
+	}
+	return result
+}
+
+var (
+	quotes = []stringDelimiter{{start: `"`, end: `"`}, {start: `'`, end: `'`}}
+	// In some languages single quotes are used not only for strings (e.g. lifetimes in Rust), so they are not highlighted
+	doubleQuotes       = []stringDelimiter{{start: `"`, end: `"`}}
+	backtickAndQuotes  = []stringDelimiter{{start: "`", end: "`", isRaw: true}, {start: `"`, end: `"`}, {start: `'`, end: `'`}}
+	tripleAndQuotes    = []stringDelimiter{{start: `"""`, end: `"""`}, {start: `'''`, end: `'''`}, {start: `"`, end: `"`}, {start: `'`, end: `'`}}
+	textBlockAndQuotes = []stringDelimiter{{start: `"""`, end: `"""`}, {start: `"`, end: `"`}, {start: `'`, end: `'`}}
+
+	cStyleLiterals = words("true false NULL nullptr")
+	cStyleTypes    = words("void char short int long float double signed unsigned bool size_t int8_t int16_t int32_t int64_t uint8_t uint16_t uint32_t uint64_t")
+)
+
+var LANGUAGE_TO_RULES = map[cfg.Language]languageRules{
+	cfg.Ada: {
+		keywords:          words("abort abs abstract accept access aliased all and array at begin body case constant declare delay delta digits do else elsif end entry exception exit for function generic goto if in interface is limited loop mod new not null of or others out overriding package pragma private procedure protected raise range record rem renames requeue return reverse select separate some subtype synchronized tagged task terminate then type until use when while with xor"),
+		literals:          words("true false"),
+		types:             words("integer natural positive float boolean character string duration"),
+		stringDelimiters:  []stringDelimiter{{start: `"`, end: `"`, isRaw: true}},
+		isCaseInsensitive: true,
+	},
+	cfg.Bash: {
+		keywords:         words("if then else elif fi case esac for select while until do done in function time coproc return exit break continue local export readonly declare unset shift source alias echo eval exec set trap"),
+		literals:         words("true false"),
+		stringDelimiters: []stringDelimiter{{start: `"`, end: `"`}, {start: `'`, end: `'`, isRaw: true}},
+		hasHeredocs:      true,
+	},
+	cfg.C: {
+		keywords:         words("auto break case const continue default do else enum extern for goto if inline register restrict return sizeof static struct switch typedef union volatile while _Alignas _Alignof _Atomic _Bool _Generic _Noreturn _Static_assert _Thread_local"),
+		literals:         cStyleLiterals,
+		types:            cStyleTypes,
+		stringDelimiters: quotes,
+	},
+	cfg.Clojure: {
+		keywords:         words("def defn defn- defmacro defmulti defmethod defprotocol defrecord deftype fn let letfn loop recur if if-not when when-not cond condp case do and or not ns require import quote try catch finally throw doseq dotimes for"),
+		literals:         words("true false nil"),
+		stringDelimiters: doubleQuotes,
+	},
+	cfg.CoffeeScript: {
+		keywords:         words("and break by case catch class continue debugger default delete do else extends finally for if in instanceof is isnt loop new not of or own return super switch then this throw try typeof unless until when while yield await import export"),
+		literals:         words("true false null undefined yes no on off"),
+		stringDelimiters: tripleAndQuotes,
+	},
+	cfg.Cpp: {
+		keywords:         words("alignas alignof and asm auto break case catch class concept const consteval constexpr constinit const_cast continue co_await co_return co_yield decltype default delete do dynamic_cast else enum explicit export extern for friend goto if inline mutable namespace new noexcept not operator or private protected public register reinterpret_cast requires return sizeof static static_assert static_cast struct switch template this thread_local throw try typedef typeid typename union using virtual volatile while"),
+		literals:         cStyleLiterals,
+		types:            words("void char char8_t char16_t char32_t wchar_t short int long float double signed unsigned bool size_t string vector map set"),
+		stringDelimiters: quotes,
+	},
+	cfg.CSharp: {
+		keywords:         words("abstract as async await base break case catch checked class const continue default delegate do else enum event explicit extern finally fixed for foreach goto if implicit in interface internal is lock namespace new operator out override params private protected public readonly record ref return sealed sizeof stackalloc static struct switch this throw try typeof unchecked unsafe using var virtual volatile when where while yield"),
+		literals:         words("true false null"),
+		types:            words("bool byte char decimal double dynamic float int long object sbyte short string uint ulong ushort void"),
+		stringDelimiters: quotes,
+	},
+	cfg.D: {
+		keywords:         words("abstract alias align asm assert auto body break case cast catch class const continue debug default delegate delete deprecated do else enum export extern final finally for foreach foreach_reverse function goto if immutable import in inout interface invariant is lazy macro mixin module new nothrow out override package pragma private protected public pure ref return scope shared static struct super switch synchronized template this throw try typeid typeof union unittest version while with"),
+		literals:         words("true false null"),
+		types:            words("void bool byte ubyte short ushort int uint long ulong float double real char wchar dchar string"),
+		stringDelimiters: backtickAndQuotes,
+	},
+	cfg.Elm: {
+		keywords:         words("module exposing import as type alias port case of if then else let in"),
+		literals:         words("True False"),
+		types:            words("Int Float Bool Char String List Maybe Result Cmd Sub Html"),
+		stringDelimiters: []stringDelimiter{{start: `"""`, end: `"""`}, {start: `"`, end: `"`}},
+	},
+	cfg.EmacsLisp: {
+		keywords:         words("defun defmacro defvar defconst defcustom defgroup lambda let if when unless cond progn and or not while dolist dotimes setq setq-local require provide interactive save-excursion condition-case"),
+		literals:         words("t nil"),
+		stringDelimiters: doubleQuotes,
+	},
+	cfg.FSharp: {
+		keywords:         words("abstract and as assert base begin class default delegate do done downcast downto elif else end exception extern finally for fun function global if in inherit inline interface internal lazy let match member module mutable namespace new not of open or override private public rec return select static struct then to try type upcast use val when while with yield"),
+		literals:         words("true false null"),
+		types:            words("bool byte sbyte int16 uint16 int uint32 int64 uint64 nativeint unativeint char string decimal unit float float32 double single list option seq"),
+		stringDelimiters: []stringDelimiter{{start: `"""`, end: `"""`, isRaw: true}, {start: `"`, end: `"`}},
+	},
+	cfg.Go: {
+		keywords:         words("break case chan const continue default defer else fallthrough for func go goto if import interface map package range return select struct switch type var"),
+		literals:         words("true false nil iota"),
+		types:            words("any bool byte complex64 complex128 error float32 float64 int int8 int16 int32 int64 rune string uint uint8 uint16 uint32 uint64 uintptr"),
+		stringDelimiters: backtickAndQuotes,
+	},
+	cfg.Haskell: {
+		keywords:         words("case class data default deriving do else forall if import in infix infixl infixr instance let module newtype of qualified then type where as hiding"),
+		literals:         words("True False Nothing Just"),
+		types:            words("Int Integer Float Double Bool Char String IO Maybe Either"),
+		stringDelimiters: doubleQuotes,
+	},
+	cfg.Java: {
+		keywords:         words("abstract assert break case catch class const continue default do else enum extends final finally for goto if implements import instanceof interface native new package private protected public record return static strictfp super switch synchronized this throw throws transient try var volatile while yield"),
+		literals:         words("true false null"),
+		types:            words("boolean byte char double float int long short void String"),
+		stringDelimiters: textBlockAndQuotes,
+	},
+	cfg.JavaScript: {
+		keywords:         words("async await break case catch class const continue debugger default delete do else export extends finally for from function if import in instanceof let new of return static super switch this throw try typeof var void while with yield"),
+		literals:         words("true false null undefined NaN Infinity"),
+		stringDelimiters: backtickAndQuotes,
+	},
+	cfg.Lua: {
+		keywords:         words("and break do else elseif end for function goto if in local not or repeat return then until while"),
+		literals:         words("true false nil"),
+		stringDelimiters: []stringDelimiter{{start: "[[", end: "]]", isRaw: true}, {start: `"`, end: `"`}, {start: `'`, end: `'`}},
+	},
+	cfg.ObjectiveC: {
+		keywords:         words("auto break case const continue default do else enum extern for goto if inline register return sizeof static struct switch typedef union volatile while @interface @implementation @end @property @synthesize @protocol @class @selector @encode @try @catch @finally @throw @autoreleasepool self super"),
+		literals:         words("YES NO nil Nil NULL true false"),
+		types:            words("void char short int long float double signed unsigned BOOL id instancetype NSInteger NSUInteger"),
+		stringDelimiters: quotes,
+	},
+	cfg.Perl: {
+		keywords:         words("if elsif else unless while until for foreach do last next redo return my our local sub package use require no BEGIN END and or not eq ne lt gt le ge cmp print"),
+		stringDelimiters: []stringDelimiter{{start: `"`, end: `"`}, {start: `'`, end: `'`}},
+		hasHeredocs:      true,
+	},
+	cfg.PHP: {
+		keywords:         words("abstract and as break callable case catch class clone const continue declare default do echo else elseif empty enddeclare endfor endforeach endif endswitch endwhile enum extends final finally fn for foreach function global goto if implements include include_once instanceof insteadof interface isset list match namespace new or print private protected public readonly require require_once return static switch throw trait try unset use var while xor yield"),
+		literals:         words("true false null TRUE FALSE NULL"),
+		types:            words("array bool float int mixed object string void"),
+		stringDelimiters: quotes,
+		hasHeredocs:      true,
+	},
+	cfg.Python: {
+		keywords:         words("and as assert async await break class continue def del elif else except finally for from global if import in is lambda nonlocal not or pass raise return try while with yield match case"),
+		literals:         words("True False None"),
+		types:            words("int float str bytes bool list dict set tuple object"),
+		stringDelimiters: tripleAndQuotes,
+	},
+	cfg.Ruby: {
+		keywords:         words("alias and begin break case class def defined do else elsif end ensure for if in module next not or redo rescue retry return self super then undef unless until when while yield require attr_reader attr_writer attr_accessor"),
+		literals:         words("true false nil"),
+		stringDelimiters: quotes,
+		hasHeredocs:      true,
+	},
+	cfg.Rust: {
+		keywords:         words("as async await break const continue crate dyn else enum extern fn for if impl in let loop match mod move mut pub ref return self Self static struct super trait type unsafe use where while"),
+		literals:         words("true false None Some Ok Err"),
+		types:            words("bool char str String i8 i16 i32 i64 i128 isize u8 u16 u32 u64 u128 usize f32 f64 Vec Option Result Box"),
+		stringDelimiters: doubleQuotes,
+	},
+	cfg.Scala: {
+		keywords:         words("abstract case catch class def do else enum export extends final finally for forSome given if implicit import lazy match new object override package private protected return sealed super then this throw trait try type using val var while with yield"),
+		literals:         words("true false null"),
+		types:            words("Any AnyRef AnyVal Boolean Byte Char Double Float Int Long Nothing Short String Unit"),
+		stringDelimiters: textBlockAndQuotes,
+	},
+	cfg.Scheme: {
+		keywords:         words("define define-syntax define-record-type lambda let let* letrec if cond case and or not when unless begin do set! quote quasiquote syntax-rules import library export"),
+		stringDelimiters: doubleQuotes,
+	},
+	cfg.SQL: {
+		keywords:          words("select from where and or not insert into values update set delete create table view index drop alter add column primary key foreign references join inner left right outer full on as group by order having limit offset union all distinct case when then else end is in like between exists with returning"),
+		literals:          words("true false null"),
+		types:             words("int integer bigint smallint decimal numeric real float double boolean char varchar text date time timestamp"),
+		stringDelimiters:  []stringDelimiter{{start: `'`, end: `'`, isRaw: true}, {start: `"`, end: `"`, isRaw: true}},
+		isCaseInsensitive: true,
+	},
+	cfg.Swift: {
+		keywords:         words("associatedtype break case catch class continue default defer deinit do else enum extension fallthrough fileprivate for func guard if import in init inout internal let open operator private protocol public repeat rethrows return self Self static struct subscript super switch throw throws try typealias var where while async await"),
+		literals:         words("true false nil"),
+		types:            words("Bool Character Double Float Int Int8 Int16 Int32 Int64 String UInt UInt8 UInt16 UInt32 UInt64 Void Any"),
+		stringDelimiters: []stringDelimiter{{start: `"""`, end: `"""`}, {start: `"`, end: `"`}},
+	},
+	cfg.Text: {isPlainText: true},
+	cfg.TypeScript: {
+		keywords:         words("abstract as async await break case catch class const constructor continue debugger declare default delete do else enum export extends finally for from function if implements import in infer instanceof interface is keyof let module namespace new of private protected public readonly return static super switch this throw try type typeof var void while with yield"),
+		literals:         words("true false null undefined NaN Infinity"),
+		types:            words("any boolean never number object string symbol unknown bigint"),
+		stringDelimiters: backtickAndQuotes,
+	},
+}
+
+// Languages without rules (e.g. added in the config file) get only comments, strings and numbers highlighted
+var defaultRules = languageRules{stringDelimiters: quotes}
+
+func getRules(language cfg.Language) languageRules {
+	rules, isPresent := LANGUAGE_TO_RULES[language]
+	if !isPresent {
+		return defaultRules
+	}
+	return rules
+}
@@ -0,0 +1,305 @@
+package lexer
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"docsncode/internal/cfg"
+)
+
+type TokenType int
+
+const (
+	Plain TokenType = iota
+	Keyword
+	Literal
+	Type
+	String
+	Number
+	Comment
+)
+
+// Unquoted identifiers must be uppercase, so shift operators (e.g. x<<y) are not taken for heredocs
+var heredocStartRegexp = regexp.MustCompile(`^<<<?[-~]?(?:'([A-Za-z_]\w*)'|"([A-Za-z_]\w*)"|([A-Z_][A-Z0-9_]*))`)
+
+var numberRegexp = regexp.MustCompile(`^(0[xXbBoO][0-9a-fA-F_]+|[0-9][0-9_]*(\.[0-9][0-9_]*)?([eE][+-]?[0-9]+)?)[a-zA-Z0-9_]*`)
+
+type Token struct {
+	Type TokenType
+	Text string
+}
+
+// openToken is a string or a comment that isn't closed at the end of the code
+type openToken struct {
+	tokenType TokenType
+	end       string
+	isRaw     bool
+	// Non-raw strings with single-character delimiters are ended by the line break
+	isSingleLine bool
+	// Not empty for heredocs, which are ended by the line with the identifier instead of the end token
+	heredocIdentifier string
+}
+
+type lexer struct {
+	rules         languageRules
+	commentSyntax cfg.CommentSyntax
+	code          string
+	pos           int
+	tokens        []Token
+	// Nil if the code is ended outside of strings and comments
+	open *openToken
+}
+
+func isIdentifierRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func (l *lexer) emit(tokenType TokenType, length int) {
+	if length == 0 {
+		return
+	}
+	text := l.code[l.pos : l.pos+length]
+	l.pos += length
+	if tokenType == Plain && len(l.tokens) != 0 && l.tokens[len(l.tokens)-1].Type == Plain {
+		l.tokens[len(l.tokens)-1].Text += text
+		return
+	}
+	l.tokens = append(l.tokens, Token{Type: tokenType, Text: text})
+}
+
+func (l *lexer) isLineStart() bool {
+	return l.pos == 0 || l.code[l.pos-1] == '\n'
+}
+
+func (l *lexer) isAfterIdentifier() bool {
+	if l.pos == 0 {
+		return false
+	}
+	r, _ := utf8.DecodeLastRuneInString(l.code[:l.pos])
+	return isIdentifierRune(r)
+}
+
+// lengthUntil returns the length of the rest of the code up to and including the end token,
+// or the whole rest if there is no end token
+func (l *lexer) lengthUntil(from int, end string) (int, bool) {
+	if i := strings.Index(l.code[from:], end); i != -1 {
+		return from - l.pos + i + len(end), true
+	}
+	return len(l.code) - l.pos, false
+}
+
+func (l *lexer) lengthOfLine() int {
+	if i := strings.IndexByte(l.code[l.pos:], '\n'); i != -1 {
+		return i
+	}
+	return len(l.code) - l.pos
+}
+
+func (l *lexer) tryComment() bool {
+	rest := l.code[l.pos:]
+	// Multiline comments go first, because their start tokens are usually longer (e.g. --[[ and --)
+	for _, tokens := range l.commentSyntax.MultilineCommentTokens {
+		// Tokens like =pod in Perl are comments only at the start of the line
+		if !strings.HasPrefix(rest, tokens.Start) || (strings.HasPrefix(tokens.Start, "=") && !l.isLineStart()) {
+			continue
+		}
+		length, isClosed := l.lengthUntil(l.pos+len(tokens.Start), tokens.End)
+		if !isClosed {
+			l.open = &openToken{tokenType: Comment, end: tokens.End, isRaw: true}
+		}
+		l.emit(Comment, length)
+		return true
+	}
+	for _, token := range l.commentSyntax.SingleLineCommentTokens {
+		if strings.HasPrefix(rest, token) {
+			l.emit(Comment, l.lengthOfLine())
+			return true
+		}
+	}
+	return false
+}
+
+func (l *lexer) tryString() bool {
+	rest := l.code[l.pos:]
+	for _, delimiter := range l.rules.stringDelimiters {
+		if !strings.HasPrefix(rest, delimiter.start) {
+			continue
+		}
+
+		// Single-character delimiters of non-raw strings can't span several lines
+		isSingleLine := len(delimiter.start) == 1 && !delimiter.isRaw
+		for i := len(delimiter.start); i < len(rest); i++ {
+			switch {
+			case isSingleLine && rest[i] == '\n':
+				// Not a string (e.g. an apostrophe), the delimiter is a plain text
+				l.emit(Plain, len(delimiter.start))
+				return true
+			case !delimiter.isRaw && rest[i] == '\\':
+				i++
+			case strings.HasPrefix(rest[i:], delimiter.end):
+				l.emit(String, i+len(delimiter.end))
+				return true
+			}
+		}
+		l.open = &openToken{tokenType: String, end: delimiter.end, isRaw: delimiter.isRaw, isSingleLine: isSingleLine}
+		l.emit(String, len(rest))
+		return true
+	}
+	return false
+}
+
+// isHeredocEnd checks that the line consists of the identifier, optionally followed by a punctuation (e.g. EOT; in PHP)
+func isHeredocEnd(line, identifier string) bool {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, identifier) {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(line[len(identifier):])
+	return !isIdentifierRune(r)
+}
+
+// tryHeredoc emits the whole heredoc, from the start to the end line, as a string
+func (l *lexer) tryHeredoc() bool {
+	if !l.rules.hasHeredocs {
+		return false
+	}
+	match := heredocStartRegexp.FindStringSubmatch(l.code[l.pos:])
+	if match == nil {
+		return false
+	}
+	identifier := match[1] + match[2] + match[3]
+	l.emitHeredocUntilEnd(l.pos+l.lengthOfLine(), identifier)
+	return true
+}
+
+// emitHeredocUntilEnd emits the heredoc up to the end line, which is searched after the line ended at lineEnd
+func (l *lexer) emitHeredocUntilEnd(lineEnd int, identifier string) {
+	for lineEnd < len(l.code) {
+		lineStart := lineEnd + 1
+		lineEnd = len(l.code)
+		if i := strings.IndexByte(l.code[lineStart:], '\n'); i != -1 {
+			lineEnd = lineStart + i
+		}
+		if isHeredocEnd(l.code[lineStart:lineEnd], identifier) {
+			l.emit(String, lineEnd-l.pos)
+			return
+		}
+	}
+	l.open = &openToken{tokenType: String, heredocIdentifier: identifier}
+	l.emit(String, len(l.code)-l.pos)
+}
+
+// continueOpenToken emits the part of the open token from the start of the code up to its end
+func (l *lexer) continueOpenToken() {
+	open := l.open
+	l.open = nil
+	if open.heredocIdentifier != "" {
+		// The end line may be the first line of the code
+		l.emitHeredocUntilEnd(-1, open.heredocIdentifier)
+		return
+	}
+
+	for i := 0; i < len(l.code); i++ {
+		switch {
+		case open.isSingleLine && l.code[i] == '\n':
+			l.emit(open.tokenType, i)
+			return
+		case !open.isRaw && l.code[i] == '\\':
+			i++
+		case strings.HasPrefix(l.code[i:], open.end):
+			l.emit(open.tokenType, i+len(open.end))
+			return
+		}
+	}
+	l.open = open
+	l.emit(open.tokenType, len(l.code))
+}
+
+func (l *lexer) tryNumber() bool {
+	if l.isAfterIdentifier() {
+		return false
+	}
+	match := numberRegexp.FindString(l.code[l.pos:])
+	if match == "" {
+		return false
+	}
+	l.emit(Number, len(match))
+	return true
+}
+
+func (l *lexer) getWordType(word string) TokenType {
+	if l.rules.isCaseInsensitive {
+		word = strings.ToLower(word)
+	}
+	if _, isPresent := l.rules.keywords[word]; isPresent {
+		return Keyword
+	}
+	if _, isPresent := l.rules.literals[word]; isPresent {
+		return Literal
+	}
+	if _, isPresent := l.rules.types[word]; isPresent {
+		return Type
+	}
+	return Plain
+}
+
+func (l *lexer) tryWord() bool {
+	rest := l.code[l.pos:]
+	length := 0
+	// Some keywords start with @ (e.g. @interface in Objective-C)
+	if strings.HasPrefix(rest, "@") {
+		length = 1
+	}
+	for length < len(rest) {
+		r, size := utf8.DecodeRuneInString(rest[length:])
+		if !isIdentifierRune(r) {
+			break
+		}
+		length += size
+	}
+	if length == 0 || (length == 1 && rest[0] == '@') {
+		return false
+	}
+	l.emit(l.getWordType(rest[:length]), length)
+	return true
+}
+
+func (l *lexer) tokenize() []Token {
+	if l.open != nil {
+		l.continueOpenToken()
+	}
+	for l.pos < len(l.code) {
+		if l.tryComment() || l.tryHeredoc() || l.tryString() || l.tryNumber() || l.tryWord() {
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(l.code[l.pos:])
+		l.emit(Plain, size)
+	}
+	return l.tokens
+}
+
+// Tokenize splits the code into tokens according to the language.
+// Concatenation of the token texts is the code.
+func Tokenize(code string, language cfg.Language, config *cfg.Config) []Token {
+	rules := getRules(language)
+	if rules.isPlainText {
+		if code == "" {
+			return nil
+		}
+		return []Token{{Type: Plain, Text: code}}
+	}
+
+	l := &lexer{rules: rules, commentSyntax: sortedCommentSyntax(config.GetCommentSyntax(language)), code: code}
+	return l.tokenize()
+}
+
+// sortedCommentSyntax returns the syntax where longer single-line tokens go first (e.g. ;; before ;)
+func sortedCommentSyntax(commentSyntax cfg.CommentSyntax) cfg.CommentSyntax {
+	singleLineTokens := append([]string(nil), commentSyntax.SingleLineCommentTokens...)
+	sort.SliceStable(singleLineTokens, func(i, j int) bool { return len(singleLineTokens[i]) > len(singleLineTokens[j]) })
+	commentSyntax.SingleLineCommentTokens = singleLineTokens
+	return commentSyntax
+}
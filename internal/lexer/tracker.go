@@ -0,0 +1,32 @@
+package lexer
+
+import "docsncode/internal/cfg"
+
+// Tracker follows the code line by line and tells whether the next line starts inside a string
+// or a comment that began on the previous lines (e.g. inside a Go raw string or a heredoc)
+type Tracker struct {
+	rules         languageRules
+	commentSyntax cfg.CommentSyntax
+	// The string or the comment that isn't closed at the end of the fed lines, nil if there is no such token
+	open *openToken
+}
+
+func NewTracker(language cfg.Language, config *cfg.Config) *Tracker {
+	return &Tracker{rules: getRules(language), commentSyntax: sortedCommentSyntax(config.GetCommentSyntax(language))}
+}
+
+// IsInsideToken returns true if the next line continues a string or a comment
+func (t *Tracker) IsInsideToken() bool {
+	return t.open != nil
+}
+
+// Feed passes the next line of the code to the tracker.
+// Only the line is scanned, the token that is open on the previous lines is continued from its start.
+func (t *Tracker) Feed(line string) {
+	if t.rules.isPlainText {
+		return
+	}
+	l := &lexer{rules: t.rules, commentSyntax: t.commentSyntax, code: line + "\n", open: t.open}
+	l.tokenize()
+	t.open = l.open
+}
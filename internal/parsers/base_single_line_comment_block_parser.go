@@ -1,29 +1,66 @@
 package parsers
 
 import (
-	"bufio"
 	"docsncode/internal/cfg"
+	"docsncode/internal/diagnostics"
+	"fmt"
 	"log"
 	"strings"
 	"unicode"
 )
 
 type baseSingleLineCommentBlockParser struct {
+	config                      *cfg.Config
 	singleLineCommentStartToken string
+	// If set, the characters of the token that are repeated after it are a part of the token too
+	// (e.g. ;; and ;;; in Lisp, --- in SQL)
+	isTokenRepeatable bool
 }
 
-func newBaseSingleLineCommentBlockParser(singleLineCommentStartToken string) CommentParser {
-	return &baseSingleLineCommentBlockParser{singleLineCommentStartToken: singleLineCommentStartToken}
+func newBaseSingleLineCommentBlockParser(config *cfg.Config, singleLineCommentStartToken string, isTokenRepeatable bool) CommentParser {
+	return &baseSingleLineCommentBlockParser{config: config, singleLineCommentStartToken: singleLineCommentStartToken, isTokenRepeatable: isTokenRepeatable}
 }
 
-func (p *baseSingleLineCommentBlockParser) Trigger(line string) bool {
-	line = strings.TrimSpace(line)
+// NewDashDashCommentBlockParser parses blocks of -- comments (e.g. Lua, Ada, SQL, Haskell)
+func NewDashDashCommentBlockParser(config *cfg.Config) CommentParser {
+	return newBaseSingleLineCommentBlockParser(config, "--", true)
+}
+
+// NewSemicolonCommentBlockParser parses blocks of ; comments (e.g. Lisps)
+func NewSemicolonCommentBlockParser(config *cfg.Config) CommentParser {
+	return newBaseSingleLineCommentBlockParser(config, ";", true)
+}
+
+func NewSingleLineCommentBlockParser(config *cfg.Config, singleLineCommentStartToken string) CommentParser {
+	switch singleLineCommentStartToken {
+	case "--":
+		return NewDashDashCommentBlockParser(config)
+	case ";":
+		return NewSemicolonCommentBlockParser(config)
+	}
+	return newBaseSingleLineCommentBlockParser(config, singleLineCommentStartToken, false)
+}
+
+// trimCommentToken removes the comment token from the line without leading spaces.
+// Returns false if the line doesn't start with the token.
+func (p *baseSingleLineCommentBlockParser) trimCommentToken(line string) (string, bool) {
 	if !strings.HasPrefix(line, p.singleLineCommentStartToken) {
-		return false
+		return line, false
 	}
 	line = strings.TrimPrefix(line, p.singleLineCommentStartToken)
+	if p.isTokenRepeatable {
+		line = strings.TrimLeft(line, p.singleLineCommentStartToken)
+	}
+	return line, true
+}
+
+func (p *baseSingleLineCommentBlockParser) Trigger(line string) bool {
+	line, isComment := p.trimCommentToken(strings.TrimSpace(line))
+	if !isComment {
+		return false
+	}
 	line = strings.TrimSpace(line)
-	return strings.HasPrefix(line, cfg.COMMENT_BLOCK_START_TOKEN)
+	return HasStartMarkerPrefix(line, p.config.CommentBlockStartToken)
 }
 
 // TODO: remove Fatalf
@@ -42,41 +79,46 @@ func (p *baseSingleLineCommentBlockParser) extractIndentFromSingleLineCommentBlo
 }
 
 func (p *baseSingleLineCommentBlockParser) isSingleLineCommentBlockEnd(line string) bool {
-	line = strings.TrimSpace(line)
-	if !strings.HasPrefix(line, p.singleLineCommentStartToken) {
+	line, isComment := p.trimCommentToken(strings.TrimSpace(line))
+	if !isComment {
 		return false
 	}
-	line = strings.TrimPrefix(line, p.singleLineCommentStartToken)
 	line = strings.TrimSpace(line)
-	return strings.HasPrefix(line, cfg.COMMENT_BLOCK_END_TOKEN)
+	return HasMarkerPrefix(line, p.config.CommentBlockEndToken)
 }
 
-func (p *baseSingleLineCommentBlockParser) Parse(startLine string, scanner *bufio.Scanner) (*ParsingResult, error) {
+func (p *baseSingleLineCommentBlockParser) Parse(startLine string, scanner *LinesScanner) (*ParsingResult, error) {
 	log.Println("Start parsing single line comment block")
 
 	indent := p.extractIndentFromSingleLineCommentBlock(startLine)
-	indentSize := calculateIndentSpacesCnt(indent)
+	indentSize := calculateIndentSpacesCnt(indent, p.config.TabSize)
 
-	var content []byte
+	markerText, _ := p.trimCommentToken(strings.TrimLeftFunc(startLine, unicode.IsSpace))
+	options, foundDiagnostics := parseBlockOptions(startLine, strings.TrimLeftFunc(markerText, unicode.IsSpace), p.config.CommentBlockStartToken, scanner.LineNumber())
+
+	var contentLines []contentLine
 	for scanner.Scan() {
 		line := scanner.Text()
 
 		if p.isSingleLineCommentBlockEnd(line) {
 			log.Println("Found comment block end, stop parsing comment block raw content")
-			return &ParsingResult{Content: content, BlockIndent: indentSize}, nil
+			result := buildParsingResult(indentSize, contentLines, p.config.TabSize)
+			result.Diagnostics = foundDiagnostics
+			result.Options = options
+			return result, nil
 		}
 
-		line = strings.TrimSpace(line)
-		if !strings.HasPrefix(line, p.singleLineCommentStartToken) {
-			// TODO: make waring
-			log.Println("Line doesn't have comment start token even though we're inside comments block")
-		}
-		line = strings.TrimPrefix(line, p.singleLineCommentStartToken)
-		// TODO: trim leading spaces?
-		if len(content) != 0 {
-			content = append(content, '\n')
+		content, isComment := p.trimCommentToken(strings.TrimLeftFunc(line, unicode.IsSpace))
+		if !isComment && content != "" {
+			foundDiagnostics = append(foundDiagnostics, diagnostics.Diagnostic{
+				Line:     scanner.LineNumber(),
+				Column:   calculateColumn(line, content),
+				Severity: diagnostics.SeverityWarning,
+				Code:     diagnostics.CodeMissingCommentToken,
+				Message:  fmt.Sprintf("line inside comment block doesn't start with comment token %q", p.singleLineCommentStartToken),
+			})
 		}
-		content = append(content, line...)
+		contentLines = append(contentLines, contentLine{sourceLine: line, start: len(line) - len(content)})
 	}
 
 	return nil, ErrCommentBlockEndNotFound
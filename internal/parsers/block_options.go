@@ -0,0 +1,98 @@
+package parsers
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"docsncode/internal/diagnostics"
+)
+
+// BlockOptions are set in braces right after the start marker, e.g. @docsncode{collapsed id=intro class=warning}
+type BlockOptions struct {
+	// Anchor of the block
+	ID string
+	// Column of the id option in the start line, 0 if there is no id
+	IDColumn int
+	// CSS classes of the block
+	Classes []string
+	// The block is rendered folded
+	IsCollapsed bool
+	// The block is omitted from the result
+	IsHidden bool
+}
+
+var optionValueRegexp = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+// Ids like L120 are used by the line anchors
+var lineAnchorIDRegexp = regexp.MustCompile(`^L\d+`)
+
+// HasStartMarkerPrefix is like HasMarkerPrefix, but the marker can also be followed by options
+func HasStartMarkerPrefix(text, marker string) bool {
+	return HasMarkerPrefix(text, marker) || strings.HasPrefix(text, marker+"{")
+}
+
+// parseBlockOptions parses options of the start marker. The marker text must start with the marker
+// and be a suffix of the start line. Problems are reported as diagnostics with positions in the start line.
+func parseBlockOptions(startLine, markerText, marker string, lineNumber int) (BlockOptions, []diagnostics.Diagnostic) {
+	var options BlockOptions
+	var foundDiagnostics []diagnostics.Diagnostic
+	// Offset is a position in the marker text
+	addDiagnostic := func(offset int, format string, args ...any) {
+		foundDiagnostics = append(foundDiagnostics, diagnostics.Diagnostic{
+			Line:     lineNumber,
+			Column:   calculateColumn(startLine, markerText) + offset,
+			Severity: diagnostics.SeverityWarning,
+			Code:     diagnostics.CodeInvalidBlockOption,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	text := strings.TrimPrefix(markerText, marker)
+	if !strings.HasPrefix(text, "{") {
+		return options, nil
+	}
+	optionsEnd := strings.IndexByte(text, '}')
+	if optionsEnd == -1 {
+		addDiagnostic(len(marker), "block options are not closed with %q", "}")
+		return options, foundDiagnostics
+	}
+
+	optionsText := text[1:optionsEnd]
+	rest := optionsText
+	for {
+		rest = strings.TrimLeftFunc(rest, unicode.IsSpace)
+		if rest == "" {
+			break
+		}
+		option := rest
+		if i := strings.IndexFunc(rest, unicode.IsSpace); i != -1 {
+			option = rest[:i]
+		}
+
+		name, value, hasValue := strings.Cut(option, "=")
+		offset := len(marker) + 1 + len(optionsText) - len(rest)
+		switch {
+		case name == "collapsed" && !hasValue:
+			options.IsCollapsed = true
+		case name == "hidden" && !hasValue:
+			options.IsHidden = true
+		case name == "id" || name == "class":
+			if !optionValueRegexp.MatchString(value) {
+				addDiagnostic(offset, "option %q must have a value like %s=name, got %q", name, name, option)
+			} else if name == "id" && lineAnchorIDRegexp.MatchString(value) {
+				addDiagnostic(offset, "id %q is reserved for line anchors like #L120", value)
+			} else if name == "id" {
+				options.ID = value
+				options.IDColumn = calculateColumn(startLine, markerText) + offset
+			} else {
+				options.Classes = append(options.Classes, value)
+			}
+		default:
+			addDiagnostic(offset, "unknown block option %q", option)
+		}
+		rest = rest[len(option):]
+	}
+	return options, foundDiagnostics
+}
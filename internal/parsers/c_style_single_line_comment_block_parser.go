@@ -1,9 +0,0 @@
-package parsers
-
-var (
-	defaultCStyleSingleLineCommentBlockParser = newBaseSingleLineCommentBlockParser("//")
-)
-
-func NewCStyleSingleLineCommentBlockParser() CommentParser {
-	return defaultCStyleSingleLineCommentBlockParser
-}
@@ -1,8 +1,9 @@
 package parsers
 
 import (
-	"bufio"
 	"errors"
+
+	"docsncode/internal/diagnostics"
 )
 
 var (
@@ -14,10 +15,16 @@ type CommentParser interface {
 	Trigger(line string) bool
 
 	// Trigger(startLine) must be true
-	Parse(startLine string, scanner *bufio.Scanner) (*ParsingResult, error)
+	Parse(startLine string, scanner *LinesScanner) (*ParsingResult, error)
 }
 
 type ParsingResult struct {
 	Content     []byte
 	BlockIndent int
+	// ContentLineColumns[i] is a column in the source file where i-th line of Content starts.
+	// The first line of Content is the line after the start line.
+	ContentLineColumns []int
+	// Diagnostics without path
+	Diagnostics []diagnostics.Diagnostic
+	Options     BlockOptions
 }
@@ -0,0 +1,49 @@
+package parsers
+
+import (
+	"log"
+	"strings"
+	"unicode"
+
+	"docsncode/internal/cfg"
+)
+
+// Python string prefixes that can be placed before the docstring quotes (e.g. r"""@docsncode)
+const docstringPrefixes = "rRuU"
+
+// docstringCommentBlockParser parses blocks inside docstrings, e.g. """@docsncode ... @docsncode""".
+// The content is handled like the content of multiline comment blocks, the quotes play the role of the comment tokens.
+type docstringCommentBlockParser struct {
+	multilineCommentBlockParser
+}
+
+func NewDocstringCommentBlockParser(config *cfg.Config, quotesToken string) CommentParser {
+	return &docstringCommentBlockParser{
+		multilineCommentBlockParser: multilineCommentBlockParser{
+			config:                     config,
+			multilineCommentStartToken: quotesToken,
+			multilineCommentEndToken:   quotesToken,
+		},
+	}
+}
+
+// trimStringPrefix returns the line without leading spaces and the string prefix
+func (p *docstringCommentBlockParser) trimStringPrefix(line string) string {
+	line = strings.TrimLeftFunc(line, unicode.IsSpace)
+	if len(line) != 0 && strings.ContainsRune(docstringPrefixes, rune(line[0])) && strings.HasPrefix(line[1:], p.multilineCommentStartToken) {
+		return line[1:]
+	}
+	return line
+}
+
+func (p *docstringCommentBlockParser) Trigger(line string) bool {
+	return p.multilineCommentBlockParser.Trigger(p.trimStringPrefix(line))
+}
+
+func (p *docstringCommentBlockParser) Parse(startLine string, scanner *LinesScanner) (*ParsingResult, error) {
+	log.Println("Start parsing docstring comment block")
+
+	indent := startLine[:len(startLine)-len(strings.TrimLeftFunc(startLine, unicode.IsSpace))]
+	markerText, _ := p.getMarkerText(p.trimStringPrefix(startLine))
+	return p.parseContent(startLine, markerText, calculateIndentSpacesCnt(indent, p.config.TabSize), scanner)
+}
@@ -0,0 +1,106 @@
+package parsers
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"math"
+)
+
+// Lines of minified or generated code can be very long, so the buffer grows until the line fits
+const initialLineBufferSize = 64 * 1024
+
+// scanLines splits the data by "\n", "\r\n" and "\r" line ends, which are not included into the lines
+func scanLines(data []byte, atEOF bool) (int, []byte, error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexAny(data, "\r\n"); i != -1 {
+		if data[i] == '\n' {
+			return i + 1, data[:i], nil
+		}
+		if i+1 < len(data) {
+			if data[i+1] == '\n' {
+				return i + 2, data[:i], nil
+			}
+			return i + 1, data[:i], nil
+		}
+		if atEOF {
+			return i + 1, data[:i], nil
+		}
+		// "\r" is the last byte, "\n" may follow it
+		return 0, nil, nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// LinesScanner reads the file line by line and remembers the number of the current line.
+// Lines read after Mark can be read again after Rewind, e.g. when a comment block turns out to be unterminated.
+type LinesScanner struct {
+	scanner    *bufio.Scanner
+	lineNumber int
+	line       string
+
+	isMarked    bool
+	markedLines []string
+	// Rewound lines, they are returned before the rest of the file
+	pendingLines []string
+}
+
+func NewLinesScanner(r io.Reader) *LinesScanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, initialLineBufferSize), math.MaxInt)
+	scanner.Split(scanLines)
+	return &LinesScanner{scanner: scanner}
+}
+
+func (s *LinesScanner) Scan() bool {
+	if len(s.pendingLines) != 0 {
+		s.line = s.pendingLines[0]
+		s.pendingLines = s.pendingLines[1:]
+	} else if s.scanner.Scan() {
+		s.line = s.scanner.Text()
+	} else {
+		return false
+	}
+	s.lineNumber++
+	if s.isMarked {
+		s.markedLines = append(s.markedLines, s.line)
+	}
+	return true
+}
+
+func (s *LinesScanner) Text() string {
+	return s.line
+}
+
+func (s *LinesScanner) Err() error {
+	return s.scanner.Err()
+}
+
+// LineNumber returns 1-based number of the line returned by the last Text call
+func (s *LinesScanner) LineNumber() int {
+	return s.lineNumber
+}
+
+// Mark starts remembering the lines after the current one
+func (s *LinesScanner) Mark() {
+	s.isMarked = true
+	s.markedLines = nil
+}
+
+// Unmark forgets the lines read after Mark
+func (s *LinesScanner) Unmark() {
+	s.isMarked = false
+	s.markedLines = nil
+}
+
+// Rewind returns the scanner to the line, which was current at the Mark call
+func (s *LinesScanner) Rewind() {
+	s.pendingLines = append(s.markedLines, s.pendingLines...)
+	s.lineNumber -= len(s.markedLines)
+	s.Unmark()
+}
@@ -0,0 +1,183 @@
+package parsers
+
+import (
+	"docsncode/internal/cfg"
+	"log"
+	"strings"
+	"unicode"
+)
+
+type multilineCommentBlockParser struct {
+	config                     *cfg.Config
+	multilineCommentStartToken string
+	multilineCommentEndToken   string
+}
+
+func NewMultilineCommentBlockParser(config *cfg.Config, multilineCommentStartToken, multilineCommentEndToken string) CommentParser {
+	return &multilineCommentBlockParser{
+		config:                     config,
+		multilineCommentStartToken: multilineCommentStartToken,
+		multilineCommentEndToken:   multilineCommentEndToken,
+	}
+}
+
+// gutter returns the character that can be placed at the start of every line of the comment
+// (e.g. * in Javadoc-style comments), or empty string if the comment doesn't have it
+func (p *multilineCommentBlockParser) gutter() string {
+	if strings.HasSuffix(p.multilineCommentStartToken, "*") {
+		return "*"
+	}
+	return ""
+}
+
+// trimGutter removes the gutter from the line without leading spaces.
+// Returns false if the line doesn't start with the gutter.
+func (p *multilineCommentBlockParser) trimGutter(line string) (string, bool) {
+	gutter := p.gutter()
+	if gutter == "" || !HasMarkerPrefix(line, gutter) {
+		return line, false
+	}
+	return strings.TrimPrefix(line, gutter), true
+}
+
+// getMarkerText returns the text of the start line after the comment start token.
+// Returns false if the line doesn't start with the token.
+func (p *multilineCommentBlockParser) getMarkerText(line string) (string, bool) {
+	line = strings.TrimLeftFunc(line, unicode.IsSpace)
+	if !strings.HasPrefix(line, p.multilineCommentStartToken) {
+		return "", false
+	}
+	line = strings.TrimPrefix(line, p.multilineCommentStartToken)
+	// Doc comments have the gutter after the start token, e.g. /** in Javadoc
+	if gutter := p.gutter(); gutter != "" {
+		line = strings.TrimPrefix(line, gutter)
+	}
+	return strings.TrimLeftFunc(line, unicode.IsSpace), true
+}
+
+func (p *multilineCommentBlockParser) Trigger(line string) bool {
+	markerText, isComment := p.getMarkerText(line)
+	return isComment && HasStartMarkerPrefix(markerText, p.config.CommentBlockStartToken)
+}
+
+// TODO: remove Fatalf
+func (p *multilineCommentBlockParser) extractIndentFromMultilineCommentBlock(line string) string {
+	indx := strings.Index(line, p.multilineCommentStartToken)
+	if indx == -1 {
+		log.Fatalf("The line should be start of comment block, but it isn't")
+	}
+
+	for _, r := range line[:indx] {
+		if !unicode.IsSpace(r) {
+			log.Fatalf("The line should be start of comment block, but it isn't")
+		}
+	}
+	return line[:indx]
+}
+
+func (p *multilineCommentBlockParser) isMultilineCommentBlockEnd(line string) bool {
+	line = strings.TrimSpace(line)
+	if lineWithoutGutter, hasGutter := p.trimGutter(line); hasGutter {
+		line = strings.TrimSpace(lineWithoutGutter)
+	}
+	if !strings.HasPrefix(line, p.config.CommentBlockEndToken) {
+		return false
+	}
+	line = strings.TrimPrefix(line, p.config.CommentBlockEndToken)
+	line = strings.TrimSpace(line)
+	return strings.HasPrefix(line, p.multilineCommentEndToken)
+}
+
+// Some comment end tokens must be placed at the start of the line (e.g. =cut in Perl or =end in Ruby),
+// so the block end marker can be placed on the line before the comment end token
+func (p *multilineCommentBlockParser) isStandaloneBlockEndMarker(line string) bool {
+	line = strings.TrimSpace(line)
+	if lineWithoutGutter, hasGutter := p.trimGutter(line); hasGutter {
+		line = strings.TrimSpace(lineWithoutGutter)
+	}
+	return line == p.config.CommentBlockEndToken
+}
+
+func (p *multilineCommentBlockParser) isMultilineCommentEnd(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), p.multilineCommentEndToken)
+}
+
+func (p *multilineCommentBlockParser) Parse(startLine string, scanner *LinesScanner) (*ParsingResult, error) {
+	log.Println("Start parsing multiline comment block")
+
+	indent := p.extractIndentFromMultilineCommentBlock(startLine)
+	markerText, _ := p.getMarkerText(startLine)
+	return p.parseContent(startLine, markerText, calculateIndentSpacesCnt(indent, p.config.TabSize), scanner)
+}
+
+// parseContent reads the lines after the start line until the block end
+func (p *multilineCommentBlockParser) parseContent(startLine, markerText string, indentSize int, scanner *LinesScanner) (*ParsingResult, error) {
+	options, optionsDiagnostics := parseBlockOptions(startLine, markerText, p.config.CommentBlockStartToken, scanner.LineNumber())
+
+	var lines []string
+	appendLine := func(line string) {
+		lines = append(lines, line)
+	}
+
+	var standaloneBlockEndMarkerLine *string
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if p.isMultilineCommentBlockEnd(line) || (standaloneBlockEndMarkerLine != nil && p.isMultilineCommentEnd(line)) {
+			log.Println("Found multiline comment block end, stop parsing comment block raw content")
+			result := p.buildResult(indentSize, lines)
+			result.Options = options
+			result.Diagnostics = optionsDiagnostics
+			return result, nil
+		}
+
+		if standaloneBlockEndMarkerLine != nil {
+			// The marker wasn't followed by the comment end, so it's a part of the content
+			appendLine(*standaloneBlockEndMarkerLine)
+			standaloneBlockEndMarkerLine = nil
+		}
+		if p.isStandaloneBlockEndMarker(line) {
+			standaloneBlockEndMarkerLine = &line
+			continue
+		}
+
+		appendLine(line)
+	}
+
+	return nil, ErrCommentBlockEndNotFound
+}
+
+// hasGutter checks that every non-empty line starts with the gutter placed to the right of the block indent
+func (p *multilineCommentBlockParser) hasGutter(indentSize int, lines []string) bool {
+	hasNonEmptyLines := false
+	for _, line := range lines {
+		contentLine := strings.TrimLeftFunc(line, unicode.IsSpace)
+		if contentLine == "" {
+			continue
+		}
+		hasNonEmptyLines = true
+
+		// Lines starting with * at the block indent are rather a markdown list than the gutter
+		lineIndentSize := calculateIndentSpacesCnt(line[:len(line)-len(contentLine)], p.config.TabSize)
+		if _, hasGutter := p.trimGutter(contentLine); !hasGutter || lineIndentSize <= indentSize {
+			return false
+		}
+	}
+	return hasNonEmptyLines
+}
+
+func (p *multilineCommentBlockParser) buildResult(indentSize int, lines []string) *ParsingResult {
+	hasGutter := p.hasGutter(indentSize, lines)
+
+	contentLines := make([]contentLine, 0, len(lines))
+	for _, line := range lines {
+		start := 0
+		if hasGutter {
+			contentWithGutter := strings.TrimLeftFunc(line, unicode.IsSpace)
+			content, _ := p.trimGutter(contentWithGutter)
+			start = len(line) - len(content)
+		}
+		contentLines = append(contentLines, contentLine{sourceLine: line, start: start})
+	}
+	return buildParsingResult(indentSize, contentLines, p.config.TabSize)
+}
@@ -1,9 +0,0 @@
-package parsers
-
-var (
-	defaultPythonStyleSingleLineCommentBlockParser = newBaseSingleLineCommentBlockParser("#")
-)
-
-func NewPythonStyleSingleLineCommentBlockParser() CommentParser {
-	return defaultPythonStyleSingleLineCommentBlockParser
-}
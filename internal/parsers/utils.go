@@ -1,13 +1,105 @@
 package parsers
 
-func calculateIndentSpacesCnt(indent string) int {
+import (
+	"strings"
+	"unicode"
+)
+
+// getNextTabStop returns the width of the indentation after a tab, tabs are expanded to the next multiple of the tab size
+func getNextTabStop(width, tabSize int) int {
+	return width + tabSize - width%tabSize
+}
+
+func calculateIndentSpacesCnt(indent string, tabSize int) int {
 	cnt := 0
 	for _, r := range indent {
 		if r == '\t' {
-			cnt += 4 // TODO: move tab size to config
+			cnt = getNextTabStop(cnt, tabSize)
 		} else {
 			cnt++
 		}
 	}
 	return cnt
 }
+
+// HasMarkerPrefix checks that the text starts with the marker followed by a space or the end of the text.
+// So "@docsncode" marker doesn't match "@docsncode-include" directive.
+func HasMarkerPrefix(text, marker string) bool {
+	if !strings.HasPrefix(text, marker) {
+		return false
+	}
+	rest := text[len(marker):]
+	return rest == "" || unicode.IsSpace(rune(rest[0]))
+}
+
+// calculateColumn returns 1-based column of the suffix in the line
+func calculateColumn(line, suffix string) int {
+	return len(line) - len(suffix) + 1
+}
+
+// contentLine is a source line of the comment block and the byte offset where its content starts
+// (i.e. after the comment token or the gutter)
+type contentLine struct {
+	sourceLine string
+	start      int
+}
+
+// calculateIndentSize returns the size of the leading spaces of the content with expanded tabs.
+// Tab stops are counted from the start of the source line, so "//\tfoo" and "// \tfoo" have the same indent.
+func calculateIndentSize(line contentLine, tabSize int) int {
+	content := line.sourceLine[line.start:]
+	indent := content[:len(content)-len(strings.TrimLeftFunc(content, unicode.IsSpace))]
+	return calculateIndentSpacesCnt(line.sourceLine[:line.start]+indent, tabSize) - calculateIndentSpacesCnt(line.sourceLine[:line.start], tabSize)
+}
+
+// removeIndent removes leading spaces of the given size from the content of the line.
+// A tab that is removed partially is replaced with spaces. Returns the content and the count of the removed bytes.
+func removeIndent(line contentLine, indentSize, tabSize int) (string, int) {
+	text := line.sourceLine[line.start:]
+	startWidth := calculateIndentSpacesCnt(line.sourceLine[:line.start], tabSize)
+	size := 0
+	for i, r := range text {
+		if size >= indentSize || !unicode.IsSpace(r) {
+			return text[i:], i
+		}
+		if r == '\t' {
+			size = getNextTabStop(startWidth+size, tabSize) - startWidth
+		} else {
+			size++
+		}
+		if size > indentSize {
+			return strings.Repeat(" ", size-indentSize) + text[i+1:], i + 1
+		}
+	}
+	return "", len(text)
+}
+
+// buildParsingResult strips the common indentation of the lines, so relative indentation
+// (e.g. of nested lists) survives, and builds the result from them
+func buildParsingResult(blockIndent int, lines []contentLine, tabSize int) *ParsingResult {
+	commonIndentSize := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line.sourceLine[line.start:]) == "" {
+			continue
+		}
+		if indentSize := calculateIndentSize(line, tabSize); commonIndentSize == -1 || indentSize < commonIndentSize {
+			commonIndentSize = indentSize
+		}
+	}
+	commonIndentSize = max(commonIndentSize, 0)
+
+	result := &ParsingResult{BlockIndent: blockIndent}
+	for i, line := range lines {
+		content, removedCnt := removeIndent(line, commonIndentSize, tabSize)
+		if i != 0 {
+			result.Content = append(result.Content, '\n')
+		}
+		// Trailing spaces of other lines are kept, because two of them are a hard line break in markdown
+		if strings.TrimSpace(content) == "" {
+			content = ""
+		}
+		result.Content = append(result.Content, content...)
+		result.ContentLineColumns = append(result.ContentLineColumns, line.start+removedCnt+1)
+	}
+	return result
+}
@@ -2,6 +2,7 @@ package paths
 
 import (
 	"path/filepath"
+	"slices"
 	"sync"
 
 	"docsncode/internal/models"
@@ -44,3 +45,28 @@ func (pp *ProcessedPaths) Update(relPathToFile models.RelPathFromResultDir) {
 		relPath = filepath.Dir(relPath)
 	}
 }
+
+// GetProcessedFiles returns sorted paths of the processed files
+func (pp *ProcessedPaths) GetProcessedFiles() []models.RelPathFromResultDir {
+	pp.mut.Lock()
+	defer pp.mut.Unlock()
+
+	return getSortedPaths(pp.processedFiles)
+}
+
+// GetProcessedDirs returns sorted paths of the processed dirs. The result dir itself is not included
+func (pp *ProcessedPaths) GetProcessedDirs() []models.RelPathFromResultDir {
+	pp.mut.Lock()
+	defer pp.mut.Unlock()
+
+	return getSortedPaths(pp.processedDirs)
+}
+
+func getSortedPaths(paths map[models.RelPathFromResultDir]struct{}) []models.RelPathFromResultDir {
+	result := make([]models.RelPathFromResultDir, 0, len(paths))
+	for path := range paths {
+		result = append(result, path)
+	}
+	slices.Sort(result)
+	return result
+}
@@ -14,3 +14,9 @@ func ConvertToPathInResultDir(pathToProjectRoot, target string, isFile bool, pat
 	}
 	return result, nil
 }
+
+// Every directory of the result gets an index page with the list of its content
+const INDEX_PAGE_FILE_NAME = "index.html"
+
+// Is rendered as the intro of the directory index page
+const README_FILE_NAME = "README.md"
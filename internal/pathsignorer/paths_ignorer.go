@@ -2,6 +2,9 @@ package pathsignorer
 
 import "docsncode/internal/models"
 
+// Ignored paths are read from this file at the project root
+const DOCSNCODE_IGNORE_FILE_NAME = ".docsncodeignore"
+
 type PathsIgnorer interface {
 	// Should be goroutine-safe
 	ShouldIgnore(path models.RelPathFromProjectRoot) bool
@@ -0,0 +1,180 @@
+package search
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+
+	"docsncode/internal/models"
+)
+
+// The index is written in two forms: JSON is read back on the next build to update the index incrementally,
+// JS is loaded by the pages with <script>, because fetch doesn't work for pages opened from the file system.
+const (
+	INDEX_JSON_FILE_NAME = "search_index.json"
+	INDEX_JS_FILE_NAME   = "search_index.js"
+)
+
+const minTermLength = 2
+
+// Document is the searchable content of one result file
+type Document struct {
+	// Path to the result file from the result dir (with slashes)
+	Path string `json:"path"`
+	// Path to the source file from the project root (with slashes)
+	Title       string   `json:"title"`
+	Headings    []string `json:"headings,omitempty"`
+	Text        string   `json:"text,omitempty"`
+	Identifiers []string `json:"identifiers,omitempty"`
+}
+
+type indexData struct {
+	Documents []Document `json:"documents"`
+	// Inverted index: term -> indexes of the documents containing it
+	Terms map[string][]int `json:"terms"`
+}
+
+// Index is a set of documents that is kept between builds.
+// Store and Retain calls are goroutine-safe.
+type Index struct {
+	documents map[string]Document
+	mut       sync.Mutex
+}
+
+func NewIndex() *Index {
+	return &Index{documents: make(map[string]Document)}
+}
+
+// LoadIndex reads the index written by the previous build to the result dir.
+// If there is no index or it can't be read, an empty index is returned.
+func LoadIndex(absPathToResultDir string) *Index {
+	index := NewIndex()
+
+	absPathToIndexFile := filepath.Join(absPathToResultDir, INDEX_JSON_FILE_NAME)
+	content, err := os.ReadFile(absPathToIndexFile)
+	if os.IsNotExist(err) {
+		log.Printf("There is no search index with path %s", absPathToIndexFile)
+		return index
+	}
+	if err != nil {
+		log.Printf("error on reading search index %s: %v, will init empty index", absPathToIndexFile, err)
+		return index
+	}
+
+	var data indexData
+	if err := json.Unmarshal(content, &data); err != nil {
+		log.Printf("error on parsing search index %s: %v, will init empty index", absPathToIndexFile, err)
+		return index
+	}
+	for _, document := range data.Documents {
+		index.documents[document.Path] = document
+	}
+	return index
+}
+
+func (i *Index) HasDocument(relPathToResultFile models.RelPathFromResultDir) bool {
+	i.mut.Lock()
+	defer i.mut.Unlock()
+
+	_, isPresent := i.documents[filepath.ToSlash(string(relPathToResultFile))]
+	return isPresent
+}
+
+func (i *Index) Store(document Document) {
+	i.mut.Lock()
+	defer i.mut.Unlock()
+
+	i.documents[document.Path] = document
+}
+
+// Retain removes documents of the result files that are not in the list
+func (i *Index) Retain(relPathsToResultFiles []models.RelPathFromResultDir) {
+	i.mut.Lock()
+	defer i.mut.Unlock()
+
+	paths := make(map[string]struct{}, len(relPathsToResultFiles))
+	for _, relPath := range relPathsToResultFiles {
+		paths[filepath.ToSlash(string(relPath))] = struct{}{}
+	}
+	for path := range i.documents {
+		if _, isPresent := paths[path]; !isPresent {
+			delete(i.documents, path)
+		}
+	}
+}
+
+// splitToTerms returns lowercased words of the text
+func splitToTerms(text string) []string {
+	var terms []string
+	for _, word := range strings.FieldsFunc(text, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		if len([]rune(word)) >= minTermLength {
+			terms = append(terms, strings.ToLower(word))
+		}
+	}
+	return terms
+}
+
+func getDocumentTerms(document Document) []string {
+	terms := splitToTerms(document.Title)
+	for _, heading := range document.Headings {
+		terms = append(terms, splitToTerms(heading)...)
+	}
+	terms = append(terms, splitToTerms(document.Text)...)
+	for _, identifier := range document.Identifiers {
+		terms = append(terms, splitToTerms(identifier)...)
+	}
+	return terms
+}
+
+func (i *Index) buildData() indexData {
+	data := indexData{Documents: make([]Document, 0, len(i.documents)), Terms: make(map[string][]int)}
+	for _, document := range i.documents {
+		data.Documents = append(data.Documents, document)
+	}
+	// Documents are sorted to get the same index for the same project
+	slices.SortFunc(data.Documents, func(a, b Document) int { return strings.Compare(a.Path, b.Path) })
+
+	for documentIndex, document := range data.Documents {
+		for _, term := range getDocumentTerms(document) {
+			documents := data.Terms[term]
+			if len(documents) == 0 || documents[len(documents)-1] != documentIndex {
+				data.Terms[term] = append(documents, documentIndex)
+			}
+		}
+	}
+	return data
+}
+
+// Dump writes the index to the result dir. Returns paths of the written files from the result dir.
+func (i *Index) Dump(absPathToResultDir string) ([]models.RelPathFromResultDir, error) {
+	i.mut.Lock()
+	defer i.mut.Unlock()
+
+	indexJSON, err := json.Marshal(i.buildData())
+	if err != nil {
+		return nil, fmt.Errorf("error on encoding search index: %w", err)
+	}
+
+	err = os.WriteFile(filepath.Join(absPathToResultDir, INDEX_JSON_FILE_NAME), indexJSON, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("error on writing search index: %w", err)
+	}
+
+	var indexJS bytes.Buffer
+	indexJS.WriteString("window.DOCSNCODE_SEARCH_INDEX = ")
+	indexJS.Write(indexJSON)
+	indexJS.WriteString(";\n")
+	err = os.WriteFile(filepath.Join(absPathToResultDir, INDEX_JS_FILE_NAME), indexJS.Bytes(), 0644)
+	if err != nil {
+		return nil, fmt.Errorf("error on writing search index: %w", err)
+	}
+
+	return []models.RelPathFromResultDir{INDEX_JSON_FILE_NAME, INDEX_JS_FILE_NAME}, nil
+}
@@ -0,0 +1,234 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"docsncode/internal/models"
+	"docsncode/internal/pathsignorer"
+)
+
+// Paths that are used by the server itself. They are placed under the same prefix to do not clash with the result files.
+const (
+	SERVER_URL_PATH_PREFIX = "_docsncode"
+	// Files from the project are served here (see cfg.Config.ProjectFilesURLPath)
+	PROJECT_FILES_URL_PATH = SERVER_URL_PATH_PREFIX + "/project"
+	LIVE_RELOAD_URL_PATH   = SERVER_URL_PATH_PREFIX + "/livereload"
+)
+
+// The page is reloaded when the server reports that its result (or the directory containing it) is changed.
+// Every event has all changed paths of a rebuild, one per line.
+var liveReloadScript = []byte(`<script>
+(function() {
+	var source = new EventSource("/` + LIVE_RELOAD_URL_PATH + `");
+	source.onmessage = function(event) {
+		var page = decodeURIComponent(location.pathname).replace(/^\//, "");
+		if (page === "" || page.endsWith("/")) {
+			page += "index.html";
+		}
+		var isChanged = event.data.split("\n").some(function(changedPath) {
+			return page === changedPath || page.startsWith(changedPath + "/");
+		});
+		if (isChanged) {
+			location.reload();
+		}
+	};
+})();
+</script>
+`)
+
+// Server serves the result dir with live reload and the project files that are referenced from the result.
+// Live reload clients are notified with Server-Sent Events.
+type Server struct {
+	absPathToProjectRoot string
+	absPathToResultDir   string
+	pathsIgnorer         pathsignorer.PathsIgnorer
+	mux                  *http.ServeMux
+
+	// Every client has a buffer for one batch of changed paths
+	clients map[chan []string]struct{}
+	// Guards the clients and the paths ignorer
+	mut  sync.Mutex
+	done chan struct{}
+}
+
+// NewServer creates the server. Project files that are ignored by the paths ignorer or hidden (e.g. .git or .env) are not served.
+func NewServer(absPathToProjectRoot, absPathToResultDir string, pathsIgnorer pathsignorer.PathsIgnorer) *Server {
+	s := &Server{
+		absPathToProjectRoot: absPathToProjectRoot,
+		absPathToResultDir:   absPathToResultDir,
+		pathsIgnorer:         pathsIgnorer,
+		mux:                  http.NewServeMux(),
+		clients:              make(map[chan []string]struct{}),
+		done:                 make(chan struct{}),
+	}
+
+	s.mux.HandleFunc("/"+LIVE_RELOAD_URL_PATH, s.serveLiveReload)
+	s.mux.Handle("/"+PROJECT_FILES_URL_PATH+"/", http.StripPrefix("/"+PROJECT_FILES_URL_PATH, http.HandlerFunc(s.serveProjectFile)))
+	s.mux.HandleFunc("/", s.serveResult)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) getPathsIgnorer() pathsignorer.PathsIgnorer {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return s.pathsIgnorer
+}
+
+// SetPathsIgnorer replaces the paths ignorer, e.g. when .docsncodeignore is changed
+func (s *Server) SetPathsIgnorer(pathsIgnorer pathsignorer.PathsIgnorer) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	s.pathsIgnorer = pathsIgnorer
+}
+
+// isProjectPathAllowed checks the path and all its parent dirs, because ignored dirs hide everything inside them
+func (s *Server) isProjectPathAllowed(relPath string) bool {
+	for relPath != "." && relPath != "" {
+		if strings.HasPrefix(path.Base(relPath), ".") {
+			log.Printf("hidden project path %s is not served", relPath)
+			return false
+		}
+		if s.getPathsIgnorer().ShouldIgnore(models.RelPathFromProjectRoot(filepath.FromSlash(relPath))) {
+			log.Printf("paths ignorer said to ignore %s, it's not served", relPath)
+			return false
+		}
+		relPath = path.Dir(relPath)
+	}
+	return true
+}
+
+// Only files are served, so the listings of the project dirs don't reveal ignored files
+func (s *Server) serveProjectFile(w http.ResponseWriter, r *http.Request) {
+	relPath := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+	if !s.isProjectPathAllowed(relPath) {
+		http.NotFound(w, r)
+		return
+	}
+
+	absPathToFile := filepath.Join(s.absPathToProjectRoot, filepath.FromSlash(relPath))
+	stat, err := os.Stat(absPathToFile)
+	if err != nil || stat.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+	http.ServeFile(w, r, absPathToFile)
+}
+
+// HTML pages are served with injected live reload script, other files are served as is
+func (s *Server) serveResult(w http.ResponseWriter, r *http.Request) {
+	urlPath := path.Clean("/" + r.URL.Path)
+	absPathToFile := filepath.Join(s.absPathToResultDir, filepath.FromSlash(urlPath))
+	if stat, err := os.Stat(absPathToFile); err == nil && stat.IsDir() {
+		absPathToFile = filepath.Join(absPathToFile, "index.html")
+	}
+
+	if filepath.Ext(absPathToFile) != ".html" {
+		http.FileServer(http.Dir(s.absPathToResultDir)).ServeHTTP(w, r)
+		return
+	}
+
+	content, err := os.ReadFile(absPathToFile)
+	if os.IsNotExist(err) {
+		http.FileServer(http.Dir(s.absPathToResultDir)).ServeHTTP(w, r)
+		return
+	}
+	if err != nil {
+		log.Printf("error on reading %s: %v", absPathToFile, err)
+		http.Error(w, "couldn't read file", http.StatusInternalServerError)
+		return
+	}
+
+	if i := bytes.LastIndex(content, []byte("</body>")); i != -1 {
+		content = append(content[:i:i], append(liveReloadScript, content[i:]...)...)
+	} else {
+		content = append(content, liveReloadScript...)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Write(content)
+}
+
+func (s *Server) serveLiveReload(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	// The client is registered before the response starts, so it gets all changes after connecting
+	client := make(chan []string, 1)
+	s.mut.Lock()
+	s.clients[client] = struct{}{}
+	s.mut.Unlock()
+	defer func() {
+		s.mut.Lock()
+		delete(s.clients, client)
+		s.mut.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.done:
+			return
+		case changedPaths := <-client:
+			for _, changedPath := range changedPaths {
+				fmt.Fprintf(w, "data: %s\n", changedPath)
+			}
+			fmt.Fprint(w, "\n")
+			flusher.Flush()
+		}
+	}
+}
+
+// NotifyChanged makes pages with the changed results reload. It's goroutine-safe.
+// All paths are sent to the clients as one event. If a client hasn't read the previous event yet,
+// the paths are merged into it, so no changes are lost.
+func (s *Server) NotifyChanged(changedResultPaths []models.RelPathFromResultDir) {
+	if len(changedResultPaths) == 0 {
+		return
+	}
+	urlPaths := make([]string, 0, len(changedResultPaths))
+	for _, changedPath := range changedResultPaths {
+		urlPaths = append(urlPaths, filepath.ToSlash(string(changedPath)))
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	for client := range s.clients {
+		batch := urlPaths
+		select {
+		case pending := <-client:
+			log.Printf("live reload client is slow, merge %d changed paths into the pending event", len(urlPaths))
+			batch = append(pending, urlPaths...)
+		default:
+		}
+		// Only NotifyChanged sends to the clients and it holds the lock, so the buffer is free here
+		client <- batch
+	}
+}
+
+// Close disconnects live reload clients, so the HTTP server can be shut down gracefully
+func (s *Server) Close() {
+	close(s.done)
+}
@@ -0,0 +1,184 @@
+package theme
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"docsncode/internal/models"
+)
+
+// Static files of the theme are written to this directory of the result dir
+const STATIC_DIR_NAME = "_docsncode_theme"
+
+// Static files are taken from this subdirectory of the theme dir
+const themeStaticDirName = "static"
+
+const templateFileExtension = ".html"
+
+// Templates that are executed for the pages, the other templates are included by them
+const (
+	PAGE_TEMPLATE_NAME  = "page"
+	INDEX_TEMPLATE_NAME = "index"
+)
+
+//go:embed default/*.html
+var defaultThemeFS embed.FS
+
+const defaultThemeDir = "default"
+
+// Theme is a set of the page templates and static files.
+// Every template is a file named after it, e.g. codeBlock.html.
+// Templates are html/template ones, so values are escaped according to their context.
+type Theme struct {
+	templates *template.Template
+	// Nil if the theme has no static files
+	staticFS fs.FS
+	// Hash of the template files, it's changed when any template is changed
+	fingerprint string
+}
+
+var defaultTheme = mustLoadDefault()
+
+func mustLoadDefault() *Theme {
+	digest := sha256.New()
+	templates, err := parseTemplates(template.New("docsncode"), defaultThemeFS, defaultThemeDir, digest)
+	if err != nil {
+		panic(fmt.Sprintf("error on parsing default theme: %v", err))
+	}
+	return &Theme{templates: templates, fingerprint: hex.EncodeToString(digest.Sum(nil))}
+}
+
+// Default returns the built-in theme
+func Default() *Theme {
+	return defaultTheme
+}
+
+// parseTemplates adds the templates of the dir to the set. Templates with the same names are replaced.
+// Names and contents of the templates are written to the digest.
+func parseTemplates(templates *template.Template, fsys fs.FS, dir string, digest io.Writer) (*template.Template, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("error on reading templates dir: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != templateFileExtension {
+			continue
+		}
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("error on reading template %s: %w", entry.Name(), err)
+		}
+		name := strings.TrimSuffix(entry.Name(), templateFileExtension)
+		fmt.Fprintf(digest, "%s\x00%d\x00%s", name, len(content), content)
+		_, err = templates.New(name).Parse(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("error on parsing template %s: %w", entry.Name(), err)
+		}
+	}
+	return templates, nil
+}
+
+// Load loads the theme from the dir. Templates of the dir replace the default ones with the same names,
+// so a theme can change only a part of the pages. Empty dir means the default theme.
+func Load(absPathToThemeDir string) (*Theme, error) {
+	if absPathToThemeDir == "" {
+		return Default(), nil
+	}
+	stat, err := os.Stat(absPathToThemeDir)
+	if err != nil {
+		return nil, fmt.Errorf("error on opening theme dir: %w", err)
+	}
+	if !stat.IsDir() {
+		return nil, fmt.Errorf("theme %s is not a directory", absPathToThemeDir)
+	}
+
+	// The default templates are parsed again, because executed templates can't be cloned
+	templates, err := parseTemplates(template.New("docsncode"), defaultThemeFS, defaultThemeDir, io.Discard)
+	if err != nil {
+		return nil, fmt.Errorf("error on parsing default theme: %w", err)
+	}
+	// The default templates are a part of the theme too
+	digest := sha256.New()
+	io.WriteString(digest, defaultTheme.fingerprint)
+	themeFS := os.DirFS(absPathToThemeDir)
+	templates, err = parseTemplates(templates, themeFS, ".", digest)
+	if err != nil {
+		return nil, err
+	}
+
+	theme := &Theme{templates: templates, fingerprint: hex.EncodeToString(digest.Sum(nil))}
+	if stat, err := os.Stat(filepath.Join(absPathToThemeDir, themeStaticDirName)); err == nil && stat.IsDir() {
+		theme.staticFS, err = fs.Sub(themeFS, themeStaticDirName)
+		if err != nil {
+			return nil, fmt.Errorf("error on opening static dir of theme: %w", err)
+		}
+	}
+	return theme, nil
+}
+
+// HasStatic checks whether the theme has static files, which must be written to the result dir
+func (t *Theme) HasStatic() bool {
+	return t.staticFS != nil
+}
+
+// Fingerprint returns the hash of the templates, pages must be rebuilt when it's changed
+func (t *Theme) Fingerprint() string {
+	return t.fingerprint
+}
+
+// Execute fills the template with the name, e.g. PAGE_TEMPLATE_NAME
+func (t *Theme) Execute(w io.Writer, name string, data any) error {
+	return t.templates.ExecuteTemplate(w, name, data)
+}
+
+// WriteStatic writes the static files of the theme to the static dir of the result dir.
+// Files with the same content are not rewritten. Returns paths of the files from the result dir.
+func (t *Theme) WriteStatic(absPathToResultDir string) ([]models.RelPathFromResultDir, error) {
+	if t.staticFS == nil {
+		return nil, nil
+	}
+
+	var relPaths []models.RelPathFromResultDir
+	err := fs.WalkDir(t.staticFS, ".", func(relPath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("error on reading static file %s: %w", relPath, err)
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		content, err := fs.ReadFile(t.staticFS, relPath)
+		if err != nil {
+			return fmt.Errorf("error on reading static file %s: %w", relPath, err)
+		}
+
+		relPathFromResultDir := filepath.Join(STATIC_DIR_NAME, filepath.FromSlash(relPath))
+		relPaths = append(relPaths, models.RelPathFromResultDir(relPathFromResultDir))
+		absPathToFile := filepath.Join(absPathToResultDir, relPathFromResultDir)
+
+		if currentContent, err := os.ReadFile(absPathToFile); err == nil && bytes.Equal(currentContent, content) {
+			log.Printf("static file %s is actual", absPathToFile)
+			return nil
+		}
+		err = os.MkdirAll(filepath.Dir(absPathToFile), 0755)
+		if err != nil {
+			return fmt.Errorf("couldn't create directory for %s: %w", absPathToFile, err)
+		}
+		err = os.WriteFile(absPathToFile, content, 0644)
+		if err != nil {
+			return fmt.Errorf("error on writing static file %s: %w", absPathToFile, err)
+		}
+		return nil
+	})
+	return relPaths, err
+}
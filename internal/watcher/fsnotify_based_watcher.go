@@ -0,0 +1,105 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+type fsnotifyBasedWatcher struct {
+	watcher       *fsnotify.Watcher
+	shouldSkipDir SkipDirFunc
+	events        chan string
+	done          chan struct{}
+}
+
+// fsnotify doesn't support recursive watching, so every directory is added separately.
+// Directories created after the start are added on the fly.
+func NewFSNotifyBasedWatcher(absPathToRoot string, shouldSkipDir SkipDirFunc) (Watcher, error) {
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("error on creating fsnotify watcher: %w", err)
+	}
+
+	w := &fsnotifyBasedWatcher{
+		watcher:       fsWatcher,
+		shouldSkipDir: shouldSkipDir,
+		events:        make(chan string),
+		done:          make(chan struct{}),
+	}
+	if err := w.addRecursively(absPathToRoot); err != nil {
+		fsWatcher.Close()
+		return nil, err
+	}
+
+	go w.run()
+	return w, nil
+}
+
+func (w *fsnotifyBasedWatcher) addRecursively(absPathToDir string) error {
+	return filepath.WalkDir(absPathToDir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		if w.shouldSkipDir(path) {
+			log.Printf("won't watch %s directory", path)
+			return filepath.SkipDir
+		}
+		if err := w.watcher.Add(path); err != nil {
+			return fmt.Errorf("couldn't watch %s: %w", path, err)
+		}
+		return nil
+	})
+}
+
+func (w *fsnotifyBasedWatcher) run() {
+	defer close(w.events)
+	for {
+		select {
+		case <-w.done:
+			return
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+
+			if event.Has(fsnotify.Create) {
+				stat, err := os.Stat(event.Name)
+				if err == nil && stat.IsDir() {
+					if err := w.addRecursively(event.Name); err != nil {
+						log.Printf("error on watching new directory %s: %v", event.Name, err)
+					}
+				}
+			}
+
+			select {
+			case w.events <- event.Name:
+			case <-w.done:
+				return
+			}
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("error from fsnotify watcher: %v", err)
+		}
+	}
+}
+
+func (w *fsnotifyBasedWatcher) Events() <-chan string {
+	return w.events
+}
+
+func (w *fsnotifyBasedWatcher) Close() error {
+	close(w.done)
+	return w.watcher.Close()
+}
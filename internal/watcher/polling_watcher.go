@@ -0,0 +1,114 @@
+package watcher
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// pollingWatcher walks the directory every interval and compares files with the previous walk.
+// It's slower than fsnotify-based watcher, but works everywhere (e.g. on network file systems).
+// Only files are reported: a created or removed directory is reported as its created or removed files.
+type pollingWatcher struct {
+	absPathToRoot string
+	shouldSkipDir SkipDirFunc
+	interval      time.Duration
+	events        chan string
+	done          chan struct{}
+}
+
+func NewPollingWatcher(absPathToRoot string, shouldSkipDir SkipDirFunc, interval time.Duration) Watcher {
+	w := &pollingWatcher{
+		absPathToRoot: absPathToRoot,
+		shouldSkipDir: shouldSkipDir,
+		interval:      interval,
+		events:        make(chan string),
+		done:          make(chan struct{}),
+	}
+	go w.run(w.takeSnapshot())
+	return w
+}
+
+func (w *pollingWatcher) takeSnapshot() map[string]fileState {
+	snapshot := make(map[string]fileState)
+	filepath.WalkDir(w.absPathToRoot, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			// The file could be removed during the walk, it will be reported on the next one
+			log.Printf("error on opening %s: %v", path, err)
+			return nil
+		}
+		if entry.IsDir() {
+			if path != w.absPathToRoot && w.shouldSkipDir(path) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			log.Printf("error on getting info for %s: %v", path, err)
+			return nil
+		}
+		snapshot[path] = fileState{modTime: info.ModTime(), size: info.Size()}
+		return nil
+	})
+	return snapshot
+}
+
+func (w *pollingWatcher) send(path string) bool {
+	select {
+	case w.events <- path:
+		return true
+	case <-w.done:
+		return false
+	}
+}
+
+func (w *pollingWatcher) run(previousSnapshot map[string]fileState) {
+	defer close(w.events)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+		}
+
+		currentSnapshot := w.takeSnapshot()
+		for path, state := range currentSnapshot {
+			if previousState, isPresent := previousSnapshot[path]; isPresent && previousState == state {
+				continue
+			}
+			if !w.send(path) {
+				return
+			}
+		}
+		for path := range previousSnapshot {
+			if _, isPresent := currentSnapshot[path]; isPresent {
+				continue
+			}
+			if !w.send(path) {
+				return
+			}
+		}
+		previousSnapshot = currentSnapshot
+	}
+}
+
+func (w *pollingWatcher) Events() <-chan string {
+	return w.events
+}
+
+func (w *pollingWatcher) Close() error {
+	close(w.done)
+	return nil
+}
@@ -0,0 +1,14 @@
+package watcher
+
+// Watcher reports absolute paths of files and directories that were created, modified or removed
+// inside the watched directory. Events for one path can be reported several times, so the consumer
+// is expected to debounce them.
+type Watcher interface {
+	Events() <-chan string
+
+	// After Close the events channel is closed
+	Close() error
+}
+
+// SkipDirFunc is used to do not watch some directories (e.g. the result directory or ignored directories)
+type SkipDirFunc func(absPathToDir string) bool
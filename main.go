@@ -2,16 +2,30 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/urfave/cli/v3"
 
 	"docsncode/internal/app"
 	"docsncode/internal/buildcache"
+	"docsncode/internal/cfg"
+	"docsncode/internal/diagnostics"
+	"docsncode/internal/highlight"
 	"docsncode/internal/models"
 	"docsncode/internal/pathsignorer"
+	"docsncode/internal/server"
+	"docsncode/internal/theme"
 )
 
 // @docsncode
@@ -47,12 +61,226 @@ func initBuildCache(forceRebuild bool, cacheType string, absPathToProjectRoot, a
 	return cache
 }
 
+type buildSettings struct {
+	pathToProjectRoot string
+	pathToResultDir   string
+	config            *cfg.Config
+	buildCache        buildcache.BuildCache
+	pathsIgnorer      pathsignorer.PathsIgnorer
+}
+
+// Returns <path-to-project-root> <path-to-result-dir> [path-to-cache-file] positional args
+func parsePositionalArgs(c *cli.Command) (string, string, string) {
+	if c.Args().Len() < 1 {
+		log.Fatal("path-to-project-root is not provided")
+	}
+	if c.Args().Len() < 2 {
+		log.Fatal("path-to-result-dir is not provided")
+	}
+	if c.Args().Len() > 3 {
+		log.Fatal("Too many positional args")
+	}
+	return c.Args().Get(0), c.Args().Get(1), c.Args().Get(2)
+}
+
+// Serve results are built to the same temporary directory for the same project,
+// so the build cache works between serve runs
+func getDefaultServeDir(pathToProjectRoot string) string {
+	absPathToProjectRoot, err := filepath.Abs(pathToProjectRoot)
+	if err != nil {
+		log.Fatalf("error on getting abs path to project root: %v", err)
+	}
+	hash := sha256.Sum256([]byte(absPathToProjectRoot))
+	return filepath.Join(os.TempDir(), "docsncode-serve", hex.EncodeToString(hash[:8]))
+}
+
+func initBuildSettings(c *cli.Command, pathToProjectRoot, pathToResultDir, pathToCacheFile string) buildSettings {
+	if pathToCacheFile == "" {
+		pathToCacheFile = filepath.Join(pathToProjectRoot, ".docsncode_cache.json")
+	}
+	forceRebuild := c.Bool("force-rebuild")
+	cacheType := c.String("cache")
+	if cacheType == "" {
+		cacheType = "modtime"
+	}
+
+	log.Printf("path_to_project_root=%s, path_to_result_dir=%s, path_to_cache_file=%s, force_rebuild=%t, cacheType=%s", pathToProjectRoot, pathToResultDir, pathToCacheFile, forceRebuild, cacheType)
+
+	absPathToProjectRoot, err := filepath.Abs(pathToProjectRoot)
+	if err != nil {
+		log.Fatalf("error on getting abs path to project root: %v", err)
+	}
+
+	absPathToResultDir, err := filepath.Abs(pathToResultDir)
+	if err != nil {
+		log.Fatalf("error on getting abs path to result dir: %v", err)
+	}
+
+	absPathToCacheDataFile, err := filepath.Abs(pathToCacheFile)
+	if err != nil {
+		log.Fatalf("error on getting abs path to cache data file: %v", err)
+	}
+
+	config, err := loadBuildConfig(c, absPathToProjectRoot)
+	if err != nil {
+		log.Fatalf("error on loading config: %v", err)
+	}
+
+	return buildSettings{
+		pathToProjectRoot: pathToProjectRoot,
+		pathToResultDir:   pathToResultDir,
+		config:            config,
+		buildCache:        initBuildCache(forceRebuild, cacheType, absPathToProjectRoot, absPathToResultDir, absPathToCacheDataFile),
+		pathsIgnorer:      initPathsIgnorer(absPathToProjectRoot),
+	}
+}
+
+// loadConfigFile loads the config file from --config or from the project root
+func loadConfigFile(c *cli.Command, absPathToProjectRoot string) (*cfg.Config, error) {
+	if pathToConfigFile := c.String("config"); pathToConfigFile != "" {
+		return cfg.LoadConfig(pathToConfigFile, true)
+	}
+	return cfg.LoadConfig(filepath.Join(absPathToProjectRoot, cfg.DEFAULT_CONFIG_FILE_NAME), false)
+}
+
+// loadBuildConfig loads the config file and applies the build flags to it
+func loadBuildConfig(c *cli.Command, absPathToProjectRoot string) (*cfg.Config, error) {
+	config, err := loadConfigFile(c, absPathToProjectRoot)
+	if err != nil {
+		return nil, err
+	}
+	config.Offline = c.Bool("offline")
+	config.HideLicense = c.Bool("hide-license")
+	if codeDisplay := cfg.CodeDisplay(c.String("code-display")); codeDisplay != "" {
+		if !cfg.IsValidCodeDisplay(codeDisplay) {
+			return nil, fmt.Errorf("unknown code display %q, expected one of %v", codeDisplay, cfg.CODE_DISPLAY_MODES)
+		}
+		config.CodeDisplay = codeDisplay
+	}
+	if layout := cfg.Layout(c.String("layout")); layout != "" {
+		if !cfg.IsValidLayout(layout) {
+			return nil, fmt.Errorf("unknown layout %q, expected one of %v", layout, cfg.LAYOUTS)
+		}
+		config.Layout = layout
+	}
+	if threshold := c.Int("code-display-threshold"); threshold != 0 {
+		if threshold < 0 {
+			return nil, fmt.Errorf("code display threshold must be positive, got %d", threshold)
+		}
+		config.CodeDisplayThreshold = threshold
+	}
+	switch highlighter := c.String("highlighter"); highlighter {
+	case "", "client":
+	case "server":
+		config.ServerSideHighlighting = true
+	default:
+		return nil, fmt.Errorf("unknown highlighter %q, expected client or server", highlighter)
+	}
+	if pathToTheme := c.String("theme"); pathToTheme != "" {
+		absPathToTheme, err := filepath.Abs(pathToTheme)
+		if err != nil {
+			return nil, fmt.Errorf("error on getting abs path to theme: %w", err)
+		}
+		config.Theme, err = theme.Load(absPathToTheme)
+		if err != nil {
+			return nil, fmt.Errorf("error on loading theme: %w", err)
+		}
+	}
+	if highlightStyle := c.String("highlight-style"); highlightStyle != "" {
+		if _, err := highlight.GetStyleCSS(highlightStyle); err != nil {
+			return nil, fmt.Errorf("error on setting highlight style: %w", err)
+		}
+		config.HighlightStyle = highlightStyle
+	}
+
+	return config, nil
+}
+
+func initConfig(c *cli.Command, absPathToProjectRoot string) *cfg.Config {
+	config, err := loadConfigFile(c, absPathToProjectRoot)
+	if err != nil {
+		log.Fatalf("error on loading config: %v", err)
+	}
+	return config
+}
+
+func loadPathsIgnorer(absPathToProjectRoot string) (pathsignorer.PathsIgnorer, error) {
+	pathToDocsncodeIgnoreFile := models.RelPathFromProjectRoot(filepath.Join(absPathToProjectRoot, pathsignorer.DOCSNCODE_IGNORE_FILE_NAME))
+	return pathsignorer.NewGoGitignoreBasedPathsIgnorer(pathToDocsncodeIgnoreFile)
+}
+
+func initPathsIgnorer(absPathToProjectRoot string) pathsignorer.PathsIgnorer {
+	pathsIgnorer, err := loadPathsIgnorer(absPathToProjectRoot)
+	if err != nil {
+		log.Fatalf("error on building paths ignorer: %v", err)
+	}
+	return pathsIgnorer
+}
+
+// reloadSettings loads the config file and .docsncodeignore again when they are changed in the watch mode.
+// Flags are applied to the new config like on start.
+func reloadSettings(c *cli.Command, pathToProjectRoot string) (*cfg.Config, pathsignorer.PathsIgnorer, error) {
+	absPathToProjectRoot, err := filepath.Abs(pathToProjectRoot)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error on getting abs path to project root: %w", err)
+	}
+	config, err := loadBuildConfig(c, absPathToProjectRoot)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error on loading config: %w", err)
+	}
+	pathsIgnorer, err := loadPathsIgnorer(absPathToProjectRoot)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error on building paths ignorer: %w", err)
+	}
+	return config, pathsIgnorer, nil
+}
+
+// printDiagnostics prints paths relative to the current directory, so editors can jump to them
+func printDiagnostics(w io.Writer, pathToProjectRoot string, foundDiagnostics []diagnostics.Diagnostic, withCodes bool) {
+	for _, diagnostic := range foundDiagnostics {
+		diagnostic.Path = models.RelPathFromProjectRoot(filepath.Join(pathToProjectRoot, string(diagnostic.Path)))
+		if withCodes {
+			fmt.Fprintln(w, diagnostic.StringWithCode())
+		} else {
+			fmt.Fprintln(w, diagnostic.String())
+		}
+	}
+}
+
+func watchFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "poll",
+			Usage: "Poll the file system for changes instead of using OS notifications",
+		},
+		&cli.DurationFlag{
+			Name:  "poll-interval",
+			Usage: "Interval between file system polls",
+			Value: time.Second,
+		},
+		&cli.DurationFlag{
+			Name:  "debounce",
+			Usage: "Wait for this long after the last change before rebuilding",
+			Value: 200 * time.Millisecond,
+		},
+	}
+}
+
+func getWatchOptions(c *cli.Command) app.WatchOptions {
+	return app.WatchOptions{
+		DebounceInterval: c.Duration("debounce"),
+		ForcePolling:     c.Bool("poll"),
+		PollingInterval:  c.Duration("poll-interval"),
+	}
+}
+
 func main() {
 	log.SetOutput(os.Stderr)
 
 	cmd := &cli.Command{
 		Name:  "docsncode",
 		Usage: "An application to unite code and documentation",
+		// These flags are also available in subcommands
 		Flags: []cli.Flag{
 			&cli.BoolFlag{
 				Name:  "force-rebuild",
@@ -62,72 +290,215 @@ func main() {
 				Name:  "cache",
 				Usage: "Select cache type (none — no cache, modtime — modification-time-based cache, hash — hash-based cache)",
 			},
+			&cli.StringFlag{
+				Name:  "config",
+				Usage: "Path to config file (default: .docsncode.yaml at the project root, if it exists)",
+			},
+			&cli.BoolFlag{
+				Name:  "offline",
+				Usage: "Write highlight.js and mermaid to the result directory instead of loading them from CDNs",
+			},
+			&cli.StringFlag{
+				Name:  "highlighter",
+				Usage: "Select where code is highlighted (client — highlight.js in the browser, server — while building)",
+			},
+			&cli.StringFlag{
+				Name:  "highlight-style",
+				Usage: "Style of the server-side highlighting (" + strings.Join(highlight.StyleNames(), ", ") + ")",
+			},
+			&cli.StringFlag{
+				Name:  "code-display",
+				Usage: "Select how code blocks are shown (expanded, collapsed, auto — collapse blocks longer than --code-display-threshold lines)",
+			},
+			&cli.IntFlag{
+				Name:  "code-display-threshold",
+				Usage: "Number of lines, longer code blocks are collapsed in the auto code display mode (default: 20)",
+			},
+			&cli.StringFlag{
+				Name:  "layout",
+				Usage: "Select how comment blocks are placed (stacked — above the code, side-by-side — in the left column next to the code)",
+			},
+			&cli.BoolFlag{
+				Name:  "hide-license",
+				Usage: "Collapse the leading license comment of every file",
+			},
+			&cli.StringFlag{
+				Name:  "theme",
+				Usage: "Path to theme directory with page templates (*.html) and static files (static/), which replace parts of the default theme",
+			},
+			&cli.BoolFlag{
+				Name:  "diagnostic-codes",
+				Usage: "Print the severity and the code of every found problem, e.g. main.go:3:1: warning[unknown-directive]: ...",
+			},
 		},
-		UsageText: "docsncode <path-to-project-root> <path-to-result-dir> [path-to-cache-file] [--force-rebuild] [--cache CACHE_TYPE]",
+		UsageText: "docsncode <path-to-project-root> <path-to-result-dir> [path-to-cache-file] [--force-rebuild] [--cache CACHE_TYPE] [--config PATH] [--offline] [--highlighter client|server] [--highlight-style STYLE] [--hide-license] [--code-display expanded|collapsed|auto] [--code-display-threshold LINES] [--layout stacked|side-by-side] [--theme PATH] [--diagnostic-codes]",
 		Action: func(_ context.Context, c *cli.Command) error {
-			if c.Args().Len() < 1 {
-				log.Fatal("path-to-project-root is not provided")
-			}
-			if c.Args().Len() < 2 {
-				log.Fatal("path-to-result-dir is not provided")
-			}
-			if c.Args().Len() > 3 {
-				log.Fatal("Too many positional args")
-			}
-			pathToProjectRoot := c.Args().Get(0)
-			pathToResultDir := c.Args().Get(1)
-			pathToCacheFile := c.Args().Get(2)
-			if pathToCacheFile == "" {
-				pathToCacheFile = filepath.Join(pathToProjectRoot, ".docsncode_cache.json")
-			}
-			forceRebuild := c.Bool("force-rebuild")
-			cacheType := c.String("cache")
-			if cacheType == "" {
-				cacheType = "modtime"
-			}
-
-			log.Printf("path_to_project_root=%s, path_to_result_dir=%s, path_to_cache_file=%s, force_rebuild=%t, cacheType=%s", pathToProjectRoot, pathToResultDir, pathToCacheFile, forceRebuild, cacheType)
-
-			absPathToProjectRoot, err := filepath.Abs(pathToProjectRoot)
-			if err != nil {
-				log.Fatalf("error on getting abs path to project root: %v", err)
-			}
-
-			absPathToResultDir, err := filepath.Abs(pathToResultDir)
-			if err != nil {
-				log.Fatalf("error on getting abs path to result dir: %v", err)
-			}
-
-			absPathToCacheDataFile, err := filepath.Abs(pathToCacheFile)
-			if err != nil {
-				log.Fatalf("error on getting abs path to cache data file: %v", err)
-			}
-
-			buildCache := initBuildCache(forceRebuild, cacheType, absPathToProjectRoot, absPathToResultDir, absPathToCacheDataFile)
+			pathToProjectRoot, pathToResultDir, pathToCacheFile := parsePositionalArgs(c)
+			settings := initBuildSettings(c, pathToProjectRoot, pathToResultDir, pathToCacheFile)
 
 			// @docsncode
-			// Here we use function from [html.go](html/html.go)
+			// Here we use function from [html.go](internal/html/html.go)
 			// @docsncode
 
-			pathToDocsncodeIgnoreFile := models.RelPathFromProjectRoot(filepath.Join(absPathToProjectRoot, ".docsncodeignore"))
-			pathsIgnorer, err := pathsignorer.NewGoGitignoreBasedPathsIgnorer(pathToDocsncodeIgnoreFile)
-			if err != nil {
-				log.Fatalf("error on building paths ignorer: %v", err)
-			}
-
-			err = app.BuildDocsncode(pathToProjectRoot, pathToResultDir, buildCache, pathsIgnorer)
+			foundDiagnostics, err := app.BuildDocsncode(settings.pathToProjectRoot, settings.pathToResultDir, settings.config, settings.buildCache, settings.pathsIgnorer)
 			if err != nil {
 				log.Fatalf("error on building docsncode: %v", err)
 			}
-			log.Printf("written result to %s", pathToResultDir)
+			printDiagnostics(os.Stderr, settings.pathToProjectRoot, foundDiagnostics, c.Bool("diagnostic-codes"))
+			log.Printf("written result to %s", settings.pathToResultDir)
 			// TODO: не должны ли мы дампить кэш при ошибке?
-			err = buildCache.Dump()
+			err = settings.buildCache.Dump()
 			if err != nil {
 				log.Printf("error on dumping build cache: %v", err)
 			}
 
 			return nil
 		},
+		Commands: []*cli.Command{
+			{
+				Name:      "check",
+				Usage:     "Report problems in comment blocks without building the result. Exits with status 1 if there are any",
+				UsageText: "docsncode check <path-to-project-root> [--config PATH] [--diagnostic-codes]",
+				Action: func(_ context.Context, c *cli.Command) error {
+					if c.Args().Len() < 1 {
+						log.Fatal("path-to-project-root is not provided")
+					}
+					if c.Args().Len() > 1 {
+						log.Fatal("Too many positional args")
+					}
+					pathToProjectRoot := c.Args().Get(0)
+
+					absPathToProjectRoot, err := filepath.Abs(pathToProjectRoot)
+					if err != nil {
+						log.Fatalf("error on getting abs path to project root: %v", err)
+					}
+
+					foundDiagnostics, err := app.CheckDocsncode(absPathToProjectRoot, initConfig(c, absPathToProjectRoot), initPathsIgnorer(absPathToProjectRoot))
+					if err != nil {
+						return fmt.Errorf("error on checking docsncode: %w", err)
+					}
+
+					printDiagnostics(os.Stdout, pathToProjectRoot, foundDiagnostics, c.Bool("diagnostic-codes"))
+					if len(foundDiagnostics) != 0 {
+						log.Printf("found %d problems", len(foundDiagnostics))
+						os.Exit(1)
+					}
+					return nil
+				},
+			},
+			{
+				Name:      "watch",
+				Usage:     "Build the result and rebuild changed files until interrupted",
+				UsageText: "docsncode watch <path-to-project-root> <path-to-result-dir> [path-to-cache-file] [--poll] [--debounce DURATION]",
+				Flags:     watchFlags(),
+				Action: func(ctx context.Context, c *cli.Command) error {
+					pathToProjectRoot, pathToResultDir, pathToCacheFile := parsePositionalArgs(c)
+					settings := initBuildSettings(c, pathToProjectRoot, pathToResultDir, pathToCacheFile)
+
+					ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+					defer stop()
+
+					watchOptions := getWatchOptions(c)
+					watchOptions.ReloadSettings = func() (*cfg.Config, pathsignorer.PathsIgnorer, error) {
+						return reloadSettings(c, settings.pathToProjectRoot)
+					}
+					watchErr := app.WatchDocsncode(ctx, settings.pathToProjectRoot, settings.pathToResultDir, settings.config, settings.buildCache, settings.pathsIgnorer, watchOptions)
+
+					// The cache is dumped even on error, so the files that were already built won't be rebuilt
+					err := settings.buildCache.Dump()
+					if err != nil {
+						log.Printf("error on dumping build cache: %v", err)
+					}
+
+					if watchErr != nil {
+						return fmt.Errorf("error on watching docsncode: %w", watchErr)
+					}
+					return nil
+				},
+			},
+			{
+				Name:      "serve",
+				Usage:     "Serve the result over HTTP and reload opened pages when their sources change",
+				UsageText: "docsncode serve <path-to-project-root> [path-to-result-dir] [path-to-cache-file] [--addr ADDRESS]",
+				Flags: append(watchFlags(),
+					&cli.StringFlag{
+						Name:  "addr",
+						Usage: "Address to listen on",
+						Value: "localhost:8000",
+					},
+				),
+				Action: func(ctx context.Context, c *cli.Command) error {
+					if c.Args().Len() < 1 {
+						log.Fatal("path-to-project-root is not provided")
+					}
+					if c.Args().Len() > 3 {
+						log.Fatal("Too many positional args")
+					}
+					pathToProjectRoot := c.Args().Get(0)
+					pathToResultDir := c.Args().Get(1)
+					pathToCacheFile := c.Args().Get(2)
+					if pathToResultDir == "" {
+						serveDir := getDefaultServeDir(pathToProjectRoot)
+						pathToResultDir = filepath.Join(serveDir, "result")
+						if pathToCacheFile == "" {
+							pathToCacheFile = filepath.Join(serveDir, "cache.json")
+						}
+					}
+					settings := initBuildSettings(c, pathToProjectRoot, pathToResultDir, pathToCacheFile)
+					settings.config.ProjectFilesURLPath = server.PROJECT_FILES_URL_PATH
+
+					absPathToProjectRoot, err := filepath.Abs(settings.pathToProjectRoot)
+					if err != nil {
+						log.Fatalf("error on getting abs path to project root: %v", err)
+					}
+					absPathToResultDir, err := filepath.Abs(settings.pathToResultDir)
+					if err != nil {
+						log.Fatalf("error on getting abs path to result dir: %v", err)
+					}
+
+					ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+					defer stop()
+
+					srv := server.NewServer(absPathToProjectRoot, absPathToResultDir, settings.pathsIgnorer)
+					httpServer := &http.Server{Addr: c.String("addr"), Handler: srv}
+					go func() {
+						log.Printf("serving %s at http://%s", absPathToResultDir, httpServer.Addr)
+						if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+							log.Printf("error on serving: %v", err)
+							stop()
+						}
+					}()
+
+					watchOptions := getWatchOptions(c)
+					watchOptions.OnRebuild = srv.NotifyChanged
+					watchOptions.ReloadSettings = func() (*cfg.Config, pathsignorer.PathsIgnorer, error) {
+						config, pathsIgnorer, err := reloadSettings(c, settings.pathToProjectRoot)
+						if err != nil {
+							return nil, nil, err
+						}
+						config.ProjectFilesURLPath = server.PROJECT_FILES_URL_PATH
+						srv.SetPathsIgnorer(pathsIgnorer)
+						return config, pathsIgnorer, nil
+					}
+					watchErr := app.WatchDocsncode(ctx, settings.pathToProjectRoot, settings.pathToResultDir, settings.config, settings.buildCache, settings.pathsIgnorer, watchOptions)
+
+					srv.Close()
+					if err := httpServer.Shutdown(context.Background()); err != nil {
+						log.Printf("error on shutting down HTTP server: %v", err)
+					}
+
+					err = settings.buildCache.Dump()
+					if err != nil {
+						log.Printf("error on dumping build cache: %v", err)
+					}
+
+					if watchErr != nil {
+						return fmt.Errorf("error on watching docsncode: %w", watchErr)
+					}
+					return nil
+				},
+			},
+		},
 	}
 
 	err := cmd.Run(context.Background(), os.Args)
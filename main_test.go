@@ -1,16 +1,32 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	compare "github.com/kilianpaquier/compare/pkg"
 	"github.com/stretchr/testify/require"
 
 	"docsncode/internal/app"
+	"docsncode/internal/assets"
 	"docsncode/internal/buildcache"
+	"docsncode/internal/cfg"
+	"docsncode/internal/charset"
+	"docsncode/internal/diagnostics"
+	"docsncode/internal/models"
 	"docsncode/internal/pathsignorer"
+	"docsncode/internal/search"
+	"docsncode/internal/server"
+	"docsncode/internal/theme"
 )
 
 // TODO: tests
@@ -47,17 +63,48 @@ func runTests(t *testing.T, testCases []testCase) {
 				resultDir = t.TempDir()
 			}
 
+			config, err := cfg.LoadConfig(filepath.Join(pathToProjectRoot, cfg.DEFAULT_CONFIG_FILE_NAME), false)
+			require.NoError(t, err)
+
 			// TODO: поддержать кэш в тестах
-			err := app.BuildDocsncode(pathToProjectRoot, resultDir, buildcache.NewAlwaysEmptyBuildCache(), pathsignorer.NewAlwaysNotIgnoringPathsIgnorer())
+			_, err = app.BuildDocsncode(pathToProjectRoot, resultDir, config, buildcache.NewAlwaysEmptyBuildCache(), pathsignorer.NewAlwaysNotIgnoringPathsIgnorer())
 
 			require.Equal(t, err, tc.expectedError)
 
+			// The fingerprint is excluded from the comparison: it's a hash of the whole config including the templates
+			// of the default theme, so any template change would change every expected result.
+			// Its effect is checked by TestConfigChangeRebuildsCachedResults.
+			pathToFingerprint := filepath.Join(resultDir, app.FINGERPRINT_FILE_NAME)
+			require.FileExists(t, pathToFingerprint)
+			require.NoError(t, os.Remove(pathToFingerprint))
+
 			err = compare.Dirs(pathToExpectedResultDir, resultDir)
 			require.NoError(t, err)
 		})
 	}
 }
 
+// buildFiles writes the files to a new project dir and builds it without cache.
+// Returns a function that reads the result file by its path from the result dir.
+func buildFiles(t *testing.T, files map[string]string, config *cfg.Config) func(name string) string {
+	sourceDir := t.TempDir()
+	resultDir := t.TempDir()
+
+	for name, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(sourceDir, name)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(sourceDir, name), []byte(content), 0644))
+	}
+
+	_, err := app.BuildDocsncode(sourceDir, resultDir, config, buildcache.NewAlwaysEmptyBuildCache(), pathsignorer.NewAlwaysNotIgnoringPathsIgnorer())
+	require.NoError(t, err)
+
+	return func(name string) string {
+		result, err := os.ReadFile(filepath.Join(resultDir, name))
+		require.NoError(t, err)
+		return string(result)
+	}
+}
+
 func TestCStyleComments(t *testing.T) {
 	testCases := []testCase{
 		{
@@ -76,6 +123,18 @@ func TestCStyleComments(t *testing.T) {
 			name:          "c_style_comments/file_with_multiline_comment_block_and_code",
 			expectedError: nil,
 		},
+		{
+			name:          "c_style_comments/file_with_javadoc_comment_blocks",
+			expectedError: nil,
+		},
+		{
+			name:          "c_style_comments/file_with_nested_markdown",
+			expectedError: nil,
+		},
+		{
+			name:          "c_style_comments/file_with_block_options",
+			expectedError: nil,
+		},
 	}
 
 	runTests(t, testCases)
@@ -91,6 +150,41 @@ func TestPythonStyleComments(t *testing.T) {
 			name:          "c_style_comments/file_with_single_line_comment_block_and_code",
 			expectedError: nil,
 		},
+		{
+			name:          "python_style_comments/file_with_docstring_comment_blocks",
+			expectedError: nil,
+		},
+	}
+
+	runTests(t, testCases)
+}
+
+func TestCommentSyntax(t *testing.T) {
+	testCases := []testCase{
+		{
+			name:          "comment_syntax/lua",
+			expectedError: nil,
+		},
+		{
+			name:          "comment_syntax/perl",
+			expectedError: nil,
+		},
+		{
+			name:          "comment_syntax/php",
+			expectedError: nil,
+		},
+		{
+			name:          "comment_syntax/sql",
+			expectedError: nil,
+		},
+		{
+			name:          "comment_syntax/haskell",
+			expectedError: nil,
+		},
+		{
+			name:          "comment_syntax/scheme",
+			expectedError: nil,
+		},
 	}
 
 	runTests(t, testCases)
@@ -116,6 +210,10 @@ func TestLinks(t *testing.T) {
 			name:          "links/link_to_website",
 			expectedError: nil,
 		},
+		{
+			name:          "links/link_with_line_anchors",
+			expectedError: nil,
+		},
 	}
 
 	runTests(t, testCases)
@@ -158,11 +256,258 @@ func TestCodeBlocks(t *testing.T) {
 			name:          "code_blocks/empty_code_blocks",
 			expectedError: nil,
 		},
+		{
+			name:          "code_blocks/fenced_code_in_comment_blocks",
+			expectedError: nil,
+		},
+		{
+			name:          "code_blocks/markers_inside_strings",
+			expectedError: nil,
+		},
+		{
+			name:          "code_blocks/hidden_regions",
+			expectedError: nil,
+		},
+		{
+			name:          "code_blocks/code_display_auto",
+			expectedError: nil,
+		},
+	}
+
+	runTests(t, testCases)
+}
+
+func TestIncludes(t *testing.T) {
+	testCases := []testCase{
+		{
+			name:          "includes/go_symbols_and_line_ranges",
+			expectedError: nil,
+		},
+	}
+
+	runTests(t, testCases)
+}
+
+func TestLayouts(t *testing.T) {
+	testCases := []testCase{
+		{
+			name:          "layouts/side_by_side",
+			expectedError: nil,
+		},
+	}
+
+	runTests(t, testCases)
+}
+
+func TestConfig(t *testing.T) {
+	testCases := []testCase{
+		{
+			name:          "config/custom_language_and_markers",
+			expectedError: nil,
+		},
+	}
+
+	runTests(t, testCases)
+}
+
+func TestIndexPages(t *testing.T) {
+	testCases := []testCase{
+		{
+			name:          "index_pages/readme_and_subdirs",
+			expectedError: nil,
+		},
 	}
 
 	runTests(t, testCases)
 }
 
+func TestInvalidConfig(t *testing.T) {
+	testCases := []struct {
+		name            string
+		content         string
+		expectedMessage string
+	}{
+		{
+			name:            "unknown field",
+			content:         "tab_sise: 2",
+			expectedMessage: "field tab_sise not found",
+		},
+		{
+			name:            "bad tab size",
+			content:         "tab_size: 0",
+			expectedMessage: "tab_size: must be between 1 and 16",
+		},
+		{
+			name:            "unknown code display",
+			content:         "code_display: folded",
+			expectedMessage: "code_display: must be one of [expanded collapsed auto], got \"folded\"",
+		},
+		{
+			name:            "unknown encoding",
+			content:         "encodings:\n  \".txt\": koi8",
+			expectedMessage: "encodings..txt: unknown encoding \"koi8\"",
+		},
+		{
+			name:            "unknown layout",
+			content:         "layout: columns",
+			expectedMessage: "layout: must be one of [stacked side-by-side], got \"columns\"",
+		},
+		{
+			name:            "marker with spaces",
+			content:         "markers:\n  block_start: \"@doc start\"",
+			expectedMessage: "markers.block_start",
+		},
+		{
+			name:            "new language without comments",
+			content:         "languages:\n  Terraform:\n    extensions: [\".tf\"]",
+			expectedMessage: "languages.Terraform: language is not built-in, so comments must be set",
+		},
+		{
+			name:            "bad extension",
+			content:         "languages:\n  Go:\n    extensions: [\"go\"]",
+			expectedMessage: "languages.Go.extensions[0]",
+		},
+		{
+			name:            "multiline comment without end",
+			content:         "languages:\n  Go:\n    comments:\n      multiline:\n        - start: \"/*\"",
+			expectedMessage: "languages.Go.comments.multiline[0]: start and end must be set together",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), cfg.DEFAULT_CONFIG_FILE_NAME)
+			require.NoError(t, os.WriteFile(path, []byte(tc.content), 0644))
+
+			_, err := cfg.LoadConfig(path, true)
+			require.ErrorContains(t, err, tc.expectedMessage)
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	sourceDir := t.TempDir()
+
+	files := map[string]string{
+		"ok.go":            "package main\n\n// @docsncode\n// [existing](ok.go)\n// @docsncode\n",
+		"include.go":       "package main\n\n// @docsncode\n// @docsncode-include missing.go\n// @docsncode-include ok.go#L2-L9\n// @docsncode-include ok.go#Missing\n// @docsncode-include ../outside.go\n// @docsncode-include secret/key.go\n// @docsncode\n",
+		"secret/key.go":    "package secret\n",
+		".docsncodeignore": "secret/\n",
+		"ids.go":           "package main\n\n// @docsncode{id=L12}\n// @docsncode\n// @docsncode{id=intro}\n// @docsncode\n// @docsncode{class=note id=intro}\n// @docsncode\n",
+		"hidden.go":        "package main\n\n// @docsncode-hide-end\n// @docsncode-hide-start\n\t// @docsncode-hide-start\n",
+		// Escaped paths and queries of existing files are not reported
+		"links.go":     "package main\n\n// @docsncode\n// [notes](my%20notes.txt), [plain](ok.go?plain=1#L2)\n// @docsncode\n",
+		"my notes.txt": "notes\n",
+		"main.go": `package main
+
+// @docsncode
+// See [missing](missing.go) and ![image](images/cat.png)
+//   @docsncode-unknown
+not a comment
+// @docsncode
+
+// @docsncode-include other.go
+
+// @docsncode{collapsed foo id=}
+// Block with options
+// @docsncode
+
+/* @docsncode
+never terminated
+`,
+	}
+	for name, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(sourceDir, name)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(sourceDir, name), []byte(content), 0644))
+	}
+
+	pathsIgnorer, err := pathsignorer.NewGoGitignoreBasedPathsIgnorer(models.RelPathFromProjectRoot(filepath.Join(sourceDir, ".docsncodeignore")))
+	require.NoError(t, err)
+
+	foundDiagnostics, err := app.CheckDocsncode(sourceDir, cfg.NewDefaultConfig(), pathsIgnorer)
+	require.NoError(t, err)
+
+	var actual []string
+	for _, diagnostic := range foundDiagnostics {
+		actual = append(actual, diagnostic.StringWithCode())
+	}
+	require.Equal(t, []string{
+		`hidden.go:3:4: warning[invalid-hidden-region]: "@docsncode-hide-end" has no matching "@docsncode-hide-start"`,
+		`hidden.go:4:4: error[invalid-hidden-region]: hidden region is not terminated with "@docsncode-hide-end"`,
+		`hidden.go:5:5: warning[invalid-hidden-region]: hidden regions can't be nested, "@docsncode-hide-start" is already at line 4`,
+		`ids.go:3:15: warning[invalid-block-option]: id "L12" is reserved for line anchors like #L120`,
+		`ids.go:7:26: warning[invalid-block-option]: id "intro" is already used by the block at line 5`,
+		`include.go:4:23: warning[invalid-include]: can't include missing.go: file is not found`,
+		`include.go:5:23: warning[invalid-include]: can't include ok.go#L2-L9: lines L2-L9 are out of the file with 5 lines`,
+		`include.go:6:23: warning[invalid-include]: can't include ok.go#Missing: symbol Missing is not found`,
+		`include.go:7:23: warning[invalid-include]: can't include ../outside.go: file is outside of the project root`,
+		`include.go:8:23: warning[invalid-include]: can't include secret/key.go: file is ignored by .docsncodeignore`,
+		`main.go:4:8: warning[missing-link-target]: link points at missing file missing.go`,
+		`main.go:4:34: warning[missing-link-target]: image points at missing file images/cat.png`,
+		`main.go:5:6: warning[unknown-directive]: unknown directive "@docsncode-unknown"`,
+		`main.go:6:1: warning[missing-comment-token]: line inside comment block doesn't start with comment token "//"`,
+		`main.go:9:4: warning[misplaced-directive]: directive "@docsncode-include" must be placed inside comment blocks`,
+		`main.go:11:25: warning[invalid-block-option]: unknown block option "foo"`,
+		`main.go:11:29: warning[invalid-block-option]: option "id" must have a value like id=name, got "id="`,
+		`main.go:15:1: error[unterminated-comment-block]: comment block is not terminated with "@docsncode", its lines are shown as code`,
+	}, actual)
+}
+
+func TestBuildReturnsDiagnostics(t *testing.T) {
+	sourceDir := t.TempDir()
+	resultDir := t.TempDir()
+
+	content := "package main\n\n/* @docsncode\nnever terminated\n*/\nfunc main() {}\n"
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "main.go"), []byte(content), 0644))
+
+	foundDiagnostics, err := app.BuildDocsncode(sourceDir, resultDir, cfg.NewDefaultConfig(), buildcache.NewAlwaysEmptyBuildCache(), pathsignorer.NewAlwaysNotIgnoringPathsIgnorer())
+	require.NoError(t, err)
+	require.Equal(t, []diagnostics.Diagnostic{{
+		Path:     "main.go",
+		Line:     3,
+		Column:   1,
+		Severity: diagnostics.SeverityError,
+		Code:     diagnostics.CodeUnterminatedCommentBlock,
+		Message:  `comment block is not terminated with "@docsncode", its lines are shown as code`,
+	}}, foundDiagnostics)
+	require.Equal(t, `main.go:3:1: comment block is not terminated with "@docsncode", its lines are shown as code`, foundDiagnostics[0].String())
+
+	// Lines of the unterminated block are not lost
+	result, err := os.ReadFile(filepath.Join(resultDir, "main.go.html"))
+	require.NoError(t, err)
+	require.Contains(t, string(result), "/* @docsncode\nnever terminated\n*/\nfunc main() {}")
+	require.Contains(t, string(result), `<a id="L6" href="#L6">6</a>`)
+}
+
+func TestSourceEncodings(t *testing.T) {
+	utf16Content := []byte{0xFF, 0xFE}
+	for _, r := range "# @docsncode\n# Hello from UTF-16\n# @docsncode\n" {
+		utf16Content = append(utf16Content, byte(r), 0)
+	}
+	files := map[string]string{
+		"crlf.go":    "\xEF\xBB\xBF// @docsncode\r\n// # Title\r\n// @docsncode\r\npackage main\r\n",
+		"long.js":    "var x = \"" + strings.Repeat("a", 100*1024) + "\";\n// @docsncode\n// After the long line\n// @docsncode\n",
+		"latin1.txt": "caf\xe9 cr\xe8me\n",
+		"utf16.py":   string(utf16Content),
+		"forced.sh":  "echo \xc3\xa9\n",
+	}
+
+	config := cfg.NewDefaultConfig()
+	config.ExtensionToEncoding[".sh"] = charset.Windows1252
+	read := buildFiles(t, files, config)
+
+	result := read("crlf.go.html")
+	require.Contains(t, result, "<h1>Title</h1>")
+	require.Contains(t, result, "<code class=\"language-golang\">package main</code>")
+	require.NotContains(t, result, "\r")
+
+	require.Contains(t, read("long.js.html"), "<p>After the long line</p>")
+	// Files that are not valid UTF-8 are read as Windows-1252
+	require.Contains(t, read("latin1.txt.html"), "café crème")
+	require.Contains(t, read("utf16.py.html"), "<p>Hello from UTF-16</p>")
+	require.Contains(t, read("forced.sh.html"), "echo Ã©")
+}
+
 // Check that unrelated files are removed from result directory
 func TestResultDirectoryCleaning(t *testing.T) {
 	sourceDir := t.TempDir()
@@ -172,9 +517,606 @@ func TestResultDirectoryCleaning(t *testing.T) {
 	require.NoError(t, err)
 	f.Close()
 
-	err = app.BuildDocsncode(sourceDir, resultDir, buildcache.NewAlwaysEmptyBuildCache(), pathsignorer.NewAlwaysNotIgnoringPathsIgnorer())
+	_, err = app.BuildDocsncode(sourceDir, resultDir, cfg.NewDefaultConfig(), buildcache.NewAlwaysEmptyBuildCache(), pathsignorer.NewAlwaysNotIgnoringPathsIgnorer())
 	require.NoError(t, err)
 
 	err = compare.Dirs(resultDir, t.TempDir())
 	require.NoError(t, err)
 }
+
+// Check that results which are actual according to the cache are not removed from result directory
+func TestCachedResultsAreKept(t *testing.T) {
+	sourceDir := t.TempDir()
+	resultDir := t.TempDir()
+	cacheDataFile := filepath.Join(t.TempDir(), "cache.json")
+
+	err := os.WriteFile(filepath.Join(sourceDir, "main.go"), []byte("package main\n"), 0644)
+	require.NoError(t, err)
+
+	for range 2 {
+		buildCache := buildcache.NewHashBasedBuildCache(sourceDir, resultDir, cacheDataFile)
+		_, err = app.BuildDocsncode(sourceDir, resultDir, cfg.NewDefaultConfig(), buildCache, pathsignorer.NewAlwaysNotIgnoringPathsIgnorer())
+		require.NoError(t, err)
+		require.NoError(t, buildCache.Dump())
+
+		require.FileExists(t, filepath.Join(resultDir, "main.go.html"))
+	}
+}
+
+// Check that cached results are rebuilt when a new file appears in the navigation sidebar
+// Build cache doesn't know about the included files, so their changes must be tracked separately
+func TestIncludedFileChangeRebuildsCachedResults(t *testing.T) {
+	sourceDir := t.TempDir()
+	resultDir := t.TempDir()
+	cacheDataFile := filepath.Join(t.TempDir(), "cache.json")
+
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "main.go"), []byte("// @docsncode\n// @docsncode-include values.dat\n// @docsncode\npackage main\n"), 0644))
+
+	build := func() string {
+		buildCache := buildcache.NewHashBasedBuildCache(sourceDir, resultDir, cacheDataFile)
+		_, err := app.BuildDocsncode(sourceDir, resultDir, cfg.NewDefaultConfig(), buildCache, pathsignorer.NewAlwaysNotIgnoringPathsIgnorer())
+		require.NoError(t, err)
+		require.NoError(t, buildCache.Dump())
+
+		result, err := os.ReadFile(filepath.Join(resultDir, "main.go.html"))
+		require.NoError(t, err)
+		return string(result)
+	}
+
+	// The missing file is a dependency too, its creation rebuilds the page
+	require.NotContains(t, build(), "first value")
+	for _, value := range []string{"first value", "second value"} {
+		require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "values.dat"), []byte(value+"\n"), 0644))
+		require.Contains(t, build(), value)
+	}
+}
+
+func TestNavigationIsUpdatedInCachedResults(t *testing.T) {
+	sourceDir := t.TempDir()
+	resultDir := t.TempDir()
+	cacheDataFile := filepath.Join(t.TempDir(), "cache.json")
+
+	build := func() {
+		buildCache := buildcache.NewHashBasedBuildCache(sourceDir, resultDir, cacheDataFile)
+		_, err := app.BuildDocsncode(sourceDir, resultDir, cfg.NewDefaultConfig(), buildCache, pathsignorer.NewAlwaysNotIgnoringPathsIgnorer())
+		require.NoError(t, err)
+		require.NoError(t, buildCache.Dump())
+	}
+
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "main.go"), []byte("package main\n"), 0644))
+	build()
+
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "sum.go"), []byte("package main\n"), 0644))
+	build()
+
+	content, err := os.ReadFile(filepath.Join(resultDir, "main.go.html"))
+	require.NoError(t, err)
+	require.Contains(t, string(content), `href="sum.go.html"`)
+}
+
+// Check that the search index keeps documents of cached results and updates documents of rebuilt ones
+func TestSearchIndexIsUpdatedIncrementally(t *testing.T) {
+	sourceDir := t.TempDir()
+	resultDir := t.TempDir()
+	cacheDataFile := filepath.Join(t.TempDir(), "cache.json")
+
+	build := func() {
+		buildCache := buildcache.NewHashBasedBuildCache(sourceDir, resultDir, cacheDataFile)
+		_, err := app.BuildDocsncode(sourceDir, resultDir, cfg.NewDefaultConfig(), buildCache, pathsignorer.NewAlwaysNotIgnoringPathsIgnorer())
+		require.NoError(t, err)
+		require.NoError(t, buildCache.Dump())
+	}
+
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "main.go"), []byte("package main\n\n// @docsncode\n// # Entry point\n// @docsncode\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "sum.go"), []byte("package main\n\n// @docsncode\n// Adds numbers\n// @docsncode\n"), 0644))
+	build()
+
+	mainResultStat, err := os.Stat(filepath.Join(resultDir, "main.go.html"))
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "sum.go"), []byte("package main\n\n// @docsncode\n// Subtracts numbers\n// @docsncode\n"), 0644))
+	build()
+
+	newMainResultStat, err := os.Stat(filepath.Join(resultDir, "main.go.html"))
+	require.NoError(t, err)
+	require.Equal(t, mainResultStat.ModTime(), newMainResultStat.ModTime())
+
+	content, err := os.ReadFile(filepath.Join(resultDir, search.INDEX_JSON_FILE_NAME))
+	require.NoError(t, err)
+	require.Contains(t, string(content), `"headings":["Entry point"]`)
+	require.Contains(t, string(content), `"text":"Subtracts numbers"`)
+	require.NotContains(t, string(content), "Adds")
+	require.FileExists(t, filepath.Join(resultDir, search.INDEX_JS_FILE_NAME))
+}
+
+func TestMixedIndentation(t *testing.T) {
+	// "   \t" is expanded to the tab stop, so it's as wide as "\t" and the second paragraph is indented code
+	content := "/* @docsncode\n   \tparagraph\n\n\t\tcode\n@docsncode */\npackage main\n"
+	result := buildFiles(t, map[string]string{"main.go": content}, cfg.NewDefaultConfig())("main.go.html")
+	require.Contains(t, result, "<p>paragraph</p>")
+	require.Contains(t, result, "<code>code\n</code>")
+}
+
+func TestIndentationAfterCommentToken(t *testing.T) {
+	// Tab stops are counted from the start of the line, so "//\t" and "// \t" are as wide as "//  ",
+	// and the last paragraph is indented by 4 spaces relative to them
+	content := "// @docsncode\n//\tfirst\n//\n// \tsecond\n//\n//      code\n// @docsncode\npackage main\n"
+	result := buildFiles(t, map[string]string{"main.go": content}, cfg.NewDefaultConfig())("main.go.html")
+	require.Contains(t, result, "<p>first</p>")
+	require.Contains(t, result, "<p>second</p>")
+	require.Contains(t, result, "<code>code\n</code>")
+}
+
+func TestHardLineBreaks(t *testing.T) {
+	read := buildFiles(t, map[string]string{
+		"main.go": "// @docsncode\n// first  \n// second\n// @docsncode\n/* @docsncode\nthird  \nfourth\n@docsncode */\npackage main\n",
+		"main.py": "def main():\n    \"\"\"@docsncode\n    fifth  \n    sixth\n    @docsncode\"\"\"\n",
+	}, cfg.NewDefaultConfig())
+
+	result := read("main.go.html")
+	require.Contains(t, result, "first<br>\nsecond")
+	require.Contains(t, result, "third<br>\nfourth")
+
+	require.Contains(t, read("main.py.html"), "fifth<br>\nsixth")
+}
+
+// Strings and comments are tracked line by line, so long ones don't slow the build down quadratically
+func TestLongMultilineTokens(t *testing.T) {
+	lines := strings.Repeat("// @docsncode\n// Not a comment block\n// @docsncode\n", 10000)
+	goContent := "package main\n\nconst fixture = `\n" + lines + "`\n\n/*\n" + lines + "*/\n\n// @docsncode\n// After the long tokens\n// @docsncode\nfunc main() {}\n"
+	shContent := "cat <<EOF\n" + strings.ReplaceAll(lines, "//", "#") + "EOF\n# @docsncode\n# After the heredoc\n# @docsncode\n"
+
+	start := time.Now()
+	read := buildFiles(t, map[string]string{"main.go": goContent, "main.sh": shContent}, cfg.NewDefaultConfig())
+	require.Less(t, time.Since(start), 10*time.Second)
+
+	for name, text := range map[string]string{"main.go.html": "After the long tokens", "main.sh.html": "After the heredoc"} {
+		result := read(name)
+		require.Contains(t, result, "<p>"+text+"</p>")
+		require.NotContains(t, result, "<p>Not a comment block</p>")
+	}
+}
+
+func TestConfigChangeRebuildsCachedResults(t *testing.T) {
+	sourceDir := t.TempDir()
+	resultDir := t.TempDir()
+	cacheDataFile := filepath.Join(t.TempDir(), "cache.json")
+
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "main.go"), []byte("// Copyright 2024 The Authors.\n\n// @doc\n// Greeting\n// @doc\npackage main\n\nfunc main() {}\n"), 0644))
+
+	build := func(config *cfg.Config) string {
+		buildCache := buildcache.NewHashBasedBuildCache(sourceDir, resultDir, cacheDataFile)
+		_, err := app.BuildDocsncode(sourceDir, resultDir, config, buildCache, pathsignorer.NewAlwaysNotIgnoringPathsIgnorer())
+		require.NoError(t, err)
+		require.NoError(t, buildCache.Dump())
+
+		result, err := os.ReadFile(filepath.Join(resultDir, "main.go.html"))
+		require.NoError(t, err)
+		return string(result)
+	}
+
+	require.NotContains(t, build(cfg.NewDefaultConfig()), "<p>Greeting</p>")
+
+	config := cfg.NewDefaultConfig()
+	config.CommentBlockStartToken = "@doc"
+	config.CommentBlockEndToken = "@doc"
+	require.Contains(t, build(config), "<p>Greeting</p>")
+
+	config.TabSize = 8
+	require.Contains(t, build(config), "tab-size: 8ch;")
+
+	config.ServerSideHighlighting = true
+	require.Contains(t, build(config), `<span class="hl-keyword">package</span>`)
+	config.HighlightStyle = "monokai"
+	require.Contains(t, build(config), "#272822")
+	config.ServerSideHighlighting = false
+	require.Contains(t, build(config), "hljs")
+
+	collapsedCode := `<details class="docsncode-collapsed-code">`
+	config.CodeDisplay = cfg.CodeDisplayCollapsed
+	require.Contains(t, build(config), collapsedCode)
+	config.CodeDisplay = cfg.CodeDisplayAuto
+	require.NotContains(t, build(config), collapsedCode)
+	config.CodeDisplayThreshold = 2
+	require.Contains(t, build(config), collapsedCode)
+
+	config.Layout = cfg.LayoutSideBySide
+	require.Contains(t, build(config), `<div class="docsncode-section">`)
+
+	config.HideLicense = true
+	require.Contains(t, build(config), `<summary>1 line hidden</summary>`)
+
+	// Changed templates of the same theme dir rebuild the results too
+	themeDir := t.TempDir()
+	for _, title := range []string{"first", "second"} {
+		require.NoError(t, os.WriteFile(filepath.Join(themeDir, "page.html"), []byte(title+" {{.Path}}"), 0644))
+		var err error
+		config.Theme, err = theme.Load(themeDir)
+		require.NoError(t, err)
+		require.Equal(t, title+" main.go", build(config))
+	}
+	require.FileExists(t, filepath.Join(resultDir, app.FINGERPRINT_FILE_NAME))
+}
+
+func TestOffline(t *testing.T) {
+	sourceDir := t.TempDir()
+	resultDir := t.TempDir()
+	cacheDataFile := filepath.Join(t.TempDir(), "cache.json")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(sourceDir, "sub"), 0755))
+	content := "package sub\n\n// @docsncode\n// ```mermaid\n// graph TD;\n//     A-->B;\n// ```\n// @docsncode\n"
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "sub", "graph.go"), []byte(content), 0644))
+
+	build := func(offline bool) string {
+		config := cfg.NewDefaultConfig()
+		config.Offline = offline
+		buildCache := buildcache.NewHashBasedBuildCache(sourceDir, resultDir, cacheDataFile)
+		_, err := app.BuildDocsncode(sourceDir, resultDir, config, buildCache, pathsignorer.NewAlwaysNotIgnoringPathsIgnorer())
+		require.NoError(t, err)
+		require.NoError(t, buildCache.Dump())
+
+		result, err := os.ReadFile(filepath.Join(resultDir, "sub", "graph.go.html"))
+		require.NoError(t, err)
+		return string(result)
+	}
+
+	result := build(true)
+	for _, name := range []string{assets.HIGHLIGHT_JS_FILE_NAME, assets.HIGHLIGHT_CSS_FILE_NAME, assets.MERMAID_JS_FILE_NAME} {
+		require.FileExists(t, filepath.Join(resultDir, assets.ASSETS_DIR_NAME, name))
+		require.Contains(t, result, `"../`+assets.ASSETS_DIR_NAME+"/"+name+`"`)
+	}
+	require.NotContains(t, result, "https://")
+
+	// The vendored files are of the declared versions, and the offline pages use the API of this version
+	highlightJS, err := os.ReadFile(filepath.Join(resultDir, assets.ASSETS_DIR_NAME, assets.HIGHLIGHT_JS_FILE_NAME))
+	require.NoError(t, err)
+	require.Contains(t, string(highlightJS[:100]), "Highlight.js "+assets.VENDORED_HIGHLIGHT_JS_VERSION)
+	require.Contains(t, result, "hljs.initHighlighting();")
+	mermaidJS, err := os.ReadFile(filepath.Join(resultDir, assets.ASSETS_DIR_NAME, assets.MERMAID_JS_FILE_NAME))
+	require.NoError(t, err)
+	require.Contains(t, string(mermaidJS), `"`+assets.VENDORED_MERMAID_VERSION+`"`)
+
+	// Cached results must be rebuilt, because they point at the assets
+	result = build(false)
+	require.NoDirExists(t, filepath.Join(resultDir, assets.ASSETS_DIR_NAME))
+	require.Contains(t, result, assets.MERMAID_JS_CDN_URL)
+	require.Contains(t, result, assets.HIGHLIGHT_JS_CDN_URL)
+	require.Contains(t, assets.HIGHLIGHT_JS_CDN_URL, "/"+assets.HIGHLIGHT_JS_VERSION+"/")
+	require.Contains(t, assets.MERMAID_JS_CDN_URL, "mermaid@"+assets.MERMAID_VERSION+"/")
+	require.Contains(t, result, "hljs.highlightAll();")
+}
+
+func TestServerSideHighlighting(t *testing.T) {
+	config := cfg.NewDefaultConfig()
+	config.ServerSideHighlighting = true
+	config.HighlightStyle = "monokai"
+	read := buildFiles(t, map[string]string{
+		"main.go":   "package main\n\nfunc main() {\n\tprintln(\"a<b\", 42) // done\n}\n",
+		"main.fs":   "let x = \"text\"\n",
+		"notes.txt": "if x < 1 // not a comment\n",
+		"fenced.go": "// @docsncode\n// ```go\n// return nil\n// ```\n// @docsncode\n",
+	}, config)
+
+	result := read("main.go.html")
+	require.Contains(t, result, `<span class="hl-keyword">func</span> main() {`)
+	require.Contains(t, result, `<span class="hl-string">&#34;a&lt;b&#34;</span>, <span class="hl-number">42</span>) <span class="hl-comment">// done</span>`)
+	require.Contains(t, result, "#272822")
+	require.NotContains(t, result, "hljs")
+
+	// F# has no highlight.js language name, but is highlighted anyway
+	require.Contains(t, read("main.fs.html"), `<span class="hl-keyword">let</span> x = <span class="hl-string">&#34;text&#34;</span>`)
+
+	require.Contains(t, read("notes.txt.html"), "if x &lt; 1 // not a comment")
+
+	// Fenced code blocks of comment blocks are highlighted too
+	require.Contains(t, read("fenced.go.html"), `<pre class="docsncode-comment-code"><code class="docsncode-highlight"><span class="hl-keyword">return</span>`)
+}
+
+func TestHideLicense(t *testing.T) {
+	config := cfg.NewDefaultConfig()
+	config.HideLicense = true
+	read := buildFiles(t, map[string]string{
+		"main.go":  "// Copyright 2024 The Authors.\n// SPDX-License-Identifier: MIT\n\n// Package main is an example\npackage main\n",
+		"run.sh":   "#!/bin/sh\n# Licensed under the Apache License\necho hi\n",
+		"other.go": "// Package other is an example\npackage other\n",
+	}, config)
+
+	result := read("main.go.html")
+	require.Contains(t, result, `<details class="docsncode-collapsed-code"><summary>2 lines hidden</summary>`)
+	require.Contains(t, result, `<a id="L3" href="#L3">3</a>`)
+	require.Regexp(t, `</details>(?s:.*)// Package main is an example`, result)
+
+	result = read("run.sh.html")
+	require.Contains(t, result, `<summary>1 line hidden</summary>`)
+	require.Regexp(t, `#!/bin/sh(?s:.*)<details class="docsncode-collapsed-code">`, result)
+
+	require.NotContains(t, read("other.go.html"), "docsncode-collapsed-code\"><summary>")
+}
+
+func TestTheme(t *testing.T) {
+	sourceDir := t.TempDir()
+	resultDir := t.TempDir()
+	themeDir := t.TempDir()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(sourceDir, "lib"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "lib", "lib.go"), []byte("// @docsncode\n// Library\n// @docsncode\npackage lib\n"), 0644))
+
+	require.NoError(t, os.WriteFile(filepath.Join(themeDir, "styles.html"), []byte(`<link rel="stylesheet" href="{{.ThemeHref}}theme.css">`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(themeDir, "codeBlock.html"), []byte(`<pre class="my-code">{{.Content}}</pre>`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(themeDir, "index.html"), []byte(`<a href="{{.SiteRoot}}index.html">{{.Title}}</a> {{.BuildTime.Year}}`), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(themeDir, "static", "fonts"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(themeDir, "static", "theme.css"), []byte("body {color: red;}"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(themeDir, "static", "fonts", "font.html"), []byte("<p>font</p>"), 0644))
+
+	config := cfg.NewDefaultConfig()
+	var err error
+	config.Theme, err = theme.Load(themeDir)
+	require.NoError(t, err)
+	_, err = app.BuildDocsncode(sourceDir, resultDir, config, buildcache.NewAlwaysEmptyBuildCache(), pathsignorer.NewAlwaysNotIgnoringPathsIgnorer())
+	require.NoError(t, err)
+
+	result, err := os.ReadFile(filepath.Join(resultDir, "lib", "lib.go.html"))
+	require.NoError(t, err)
+	// Not overridden templates are taken from the default theme
+	require.Contains(t, string(result), `<link rel="stylesheet" href="../_docsncode_theme/theme.css">`)
+	require.Contains(t, string(result), `<pre class="my-code">package lib</pre>`)
+	require.Contains(t, string(result), `<p>Library</p>`)
+	require.Contains(t, string(result), `<nav class="docsncode-navigation">`)
+
+	index, err := os.ReadFile(filepath.Join(resultDir, "lib", "index.html"))
+	require.NoError(t, err)
+	require.Equal(t, fmt.Sprintf(`<a href="../index.html">lib</a> %d`, time.Now().Year()), string(index))
+
+	css, err := os.ReadFile(filepath.Join(resultDir, "_docsncode_theme", "theme.css"))
+	require.NoError(t, err)
+	require.Equal(t, "body {color: red;}", string(css))
+	require.FileExists(t, filepath.Join(resultDir, "_docsncode_theme", "fonts", "font.html"))
+
+	// Static files are kept on rebuilds and are not listed as results
+	_, err = app.BuildDocsncode(sourceDir, resultDir, config, buildcache.NewAlwaysEmptyBuildCache(), pathsignorer.NewAlwaysNotIgnoringPathsIgnorer())
+	require.NoError(t, err)
+	require.FileExists(t, filepath.Join(resultDir, "_docsncode_theme", "theme.css"))
+	index, err = os.ReadFile(filepath.Join(resultDir, "index.html"))
+	require.NoError(t, err)
+	require.Equal(t, fmt.Sprintf(`<a href="index.html">%s</a> %d`, filepath.Base(sourceDir), time.Now().Year()), string(index))
+}
+
+// Names of files and dirs are escaped in the markup and in the links of the pages
+func TestFileNamesAreEscaped(t *testing.T) {
+	read := buildFiles(t, map[string]string{
+		"main.go":     "package main\n",
+		`a"<&b.go`:    "package main\n",
+		`d"<&/lib.go`: "package lib\n",
+	}, cfg.NewDefaultConfig())
+
+	for _, name := range []string{"index.html", "main.go.html"} {
+		result := read(name)
+		require.Contains(t, result, `<a href="a%22%3C&amp;b.go.html">a&#34;&lt;&amp;b.go</a>`)
+		require.Contains(t, result, `<a href="d%22%3C&amp;/index.html">d&#34;&lt;&amp;/</a>`)
+		require.NotContains(t, result, `a"<&b`)
+	}
+	require.Contains(t, read(`d"<&/index.html`), `<title>d&#34;&lt;&amp;</title>`)
+
+	// Values are escaped in the templates of user themes too
+	themeDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(themeDir, "index.html"), []byte(`{{range .Files}}<a href="{{.Href}}" title="{{.Name}}">{{.Name}}</a>{{end}}`), 0644))
+	config := cfg.NewDefaultConfig()
+	var err error
+	config.Theme, err = theme.Load(themeDir)
+	require.NoError(t, err)
+	read = buildFiles(t, map[string]string{`a"<&b.go`: "package main\n"}, config)
+	require.Equal(t, `<a href="a%22%3C&amp;b.go.html" title="a&#34;&lt;&amp;b.go">a&#34;&lt;&amp;b.go</a>`, read("index.html"))
+}
+
+func TestInvalidTheme(t *testing.T) {
+	themeDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(themeDir, "page.html"), []byte(`{{if .Blocks}}`), 0644))
+	_, err := theme.Load(themeDir)
+	require.ErrorContains(t, err, "error on parsing template page.html")
+
+	_, err = theme.Load(filepath.Join(themeDir, "missing"))
+	require.Error(t, err)
+}
+
+func TestWatch(t *testing.T) {
+	sourceDir := t.TempDir()
+	resultDir := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(sourceDir, "main.go"), []byte("package main\n"), 0644)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	watchErr := make(chan error)
+	go func() {
+		watchErr <- app.WatchDocsncode(ctx, sourceDir, resultDir, cfg.NewDefaultConfig(), buildcache.NewAlwaysEmptyBuildCache(), pathsignorer.NewAlwaysNotIgnoringPathsIgnorer(), app.WatchOptions{
+			DebounceInterval: 10 * time.Millisecond,
+			ForcePolling:     true,
+			PollingInterval:  10 * time.Millisecond,
+		})
+	}()
+
+	fileExists := func(path string) func() bool {
+		return func() bool {
+			_, err := os.Stat(path)
+			return err == nil
+		}
+	}
+
+	require.Eventually(t, fileExists(filepath.Join(resultDir, "main.go.html")), 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(sourceDir, "sub"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "sub", "sum.go"), []byte("package sub\n"), 0644))
+	require.Eventually(t, fileExists(filepath.Join(resultDir, "sub", "sum.go.html")), 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, os.RemoveAll(filepath.Join(sourceDir, "sub")))
+	require.Eventually(t, func() bool { return !fileExists(filepath.Join(resultDir, "sub"))() }, 5*time.Second, 10*time.Millisecond)
+	require.FileExists(t, filepath.Join(resultDir, "main.go.html"))
+
+	// The page is rebuilt when the included file is changed, though the file has no own result
+	resultContains := func(text string) func() bool {
+		return func() bool {
+			result, err := os.ReadFile(filepath.Join(resultDir, "main.go.html"))
+			return err == nil && strings.Contains(string(result), text)
+		}
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "values.dat"), []byte("first value\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "main.go"), []byte("// @docsncode\n// @docsncode-include values.dat\n// @docsncode\npackage main\n"), 0644))
+	require.Eventually(t, resultContains("first value"), 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "values.dat"), []byte("second value\n"), 0644))
+	require.Eventually(t, resultContains("second value"), 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-watchErr)
+}
+
+func TestWatchReloadsSettings(t *testing.T) {
+	sourceDir := t.TempDir()
+	resultDir := t.TempDir()
+
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "main.go"), []byte("package main\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "secret.go"), []byte("package main\n"), 0644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	watchErr := make(chan error)
+	go func() {
+		watchErr <- app.WatchDocsncode(ctx, sourceDir, resultDir, cfg.NewDefaultConfig(), buildcache.NewAlwaysEmptyBuildCache(), pathsignorer.NewAlwaysNotIgnoringPathsIgnorer(), app.WatchOptions{
+			DebounceInterval: 10 * time.Millisecond,
+			ForcePolling:     true,
+			PollingInterval:  10 * time.Millisecond,
+			ReloadSettings: func() (*cfg.Config, pathsignorer.PathsIgnorer, error) {
+				config, err := cfg.LoadConfig(filepath.Join(sourceDir, cfg.DEFAULT_CONFIG_FILE_NAME), false)
+				if err != nil {
+					return nil, nil, err
+				}
+				pathsIgnorer, err := pathsignorer.NewGoGitignoreBasedPathsIgnorer(models.RelPathFromProjectRoot(filepath.Join(sourceDir, pathsignorer.DOCSNCODE_IGNORE_FILE_NAME)))
+				return config, pathsIgnorer, err
+			},
+		})
+	}()
+
+	resultContains := func(text string) func() bool {
+		return func() bool {
+			result, err := os.ReadFile(filepath.Join(resultDir, "main.go.html"))
+			return err == nil && strings.Contains(string(result), text)
+		}
+	}
+	require.Eventually(t, resultContains("package main"), 5*time.Second, 10*time.Millisecond)
+	require.FileExists(t, filepath.Join(resultDir, "secret.go.html"))
+
+	// The whole project is rebuilt with the new settings, results of newly ignored files are removed
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, cfg.DEFAULT_CONFIG_FILE_NAME), []byte("tab_size: 8\n"), 0644))
+	require.Eventually(t, resultContains("tab-size: 8ch;"), 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, pathsignorer.DOCSNCODE_IGNORE_FILE_NAME), []byte("secret.go\n"), 0644))
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(filepath.Join(resultDir, "secret.go.html"))
+		return os.IsNotExist(err)
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Invalid settings stop the watch instead of leaving the result built with the old ones
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, cfg.DEFAULT_CONFIG_FILE_NAME), []byte("tab_size: [\n"), 0644))
+	select {
+	case err := <-watchErr:
+		require.ErrorContains(t, err, "error on reloading settings")
+	case <-time.After(5 * time.Second):
+		cancel()
+		t.Fatal("watch is not stopped after the invalid config")
+	}
+	cancel()
+}
+
+func TestWatchStopsOnSettingsChangeWithoutReload(t *testing.T) {
+	sourceDir := t.TempDir()
+	resultDir := t.TempDir()
+
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "main.go"), []byte("package main\n"), 0644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	watchErr := make(chan error)
+	go func() {
+		watchErr <- app.WatchDocsncode(ctx, sourceDir, resultDir, cfg.NewDefaultConfig(), buildcache.NewAlwaysEmptyBuildCache(), pathsignorer.NewAlwaysNotIgnoringPathsIgnorer(), app.WatchOptions{
+			DebounceInterval: 10 * time.Millisecond,
+			ForcePolling:     true,
+			PollingInterval:  10 * time.Millisecond,
+		})
+	}()
+
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(filepath.Join(resultDir, "main.go.html"))
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, pathsignorer.DOCSNCODE_IGNORE_FILE_NAME), []byte("main.go\n"), 0644))
+	select {
+	case err := <-watchErr:
+		require.ErrorContains(t, err, "restart watch to apply them")
+	case <-time.After(5 * time.Second):
+		t.Fatal("watch is not stopped after the settings change")
+	}
+}
+
+func TestServe(t *testing.T) {
+	sourceDir := t.TempDir()
+	resultDir := t.TempDir()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(sourceDir, "sub"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(sourceDir, "secret"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(sourceDir, ".git"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "data.json"), []byte("{}"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "secret", "key.txt"), []byte("key"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, ".env"), []byte("TOKEN=1"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, ".git", "config"), []byte("[core]"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, ".docsncodeignore"), []byte("secret/\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "sub", "main.go"), []byte("// @docsncode\n// [data](../data.json)\n// @docsncode\n"), 0644))
+
+	config := cfg.NewDefaultConfig()
+	config.ProjectFilesURLPath = server.PROJECT_FILES_URL_PATH
+	_, err := app.BuildDocsncode(sourceDir, resultDir, config, buildcache.NewAlwaysEmptyBuildCache(), pathsignorer.NewAlwaysNotIgnoringPathsIgnorer())
+	require.NoError(t, err)
+
+	pathsIgnorer, err := pathsignorer.NewGoGitignoreBasedPathsIgnorer(models.RelPathFromProjectRoot(filepath.Join(sourceDir, ".docsncodeignore")))
+	require.NoError(t, err)
+	srv := server.NewServer(sourceDir, resultDir, pathsIgnorer)
+	httpServer := httptest.NewServer(srv)
+	defer httpServer.Close()
+
+	get := func(path string) string {
+		resp, err := http.Get(httpServer.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(body)
+	}
+
+	page := get("/sub/main.go.html")
+	require.Contains(t, page, `href="../_docsncode/project/data.json"`)
+	require.Contains(t, page, server.LIVE_RELOAD_URL_PATH)
+
+	require.Equal(t, "{}", get("/_docsncode/project/data.json"))
+
+	// Ignored and hidden files and listings of dirs are not served
+	for _, path := range []string{"/_docsncode/project/secret/key.txt", "/_docsncode/project/.env", "/_docsncode/project/.git/config", "/_docsncode/project/", "/_docsncode/project/sub/"} {
+		resp, err := http.Get(httpServer.URL + path)
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusNotFound, resp.StatusCode, path)
+	}
+
+	// Changes are not lost, even if there are many of them
+	resp, err := http.Get(httpServer.URL + "/" + server.LIVE_RELOAD_URL_PATH)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	expectedPaths := map[string]bool{}
+	for i := range 40 {
+		changedPath := fmt.Sprintf("dir/file%d.go.html", i)
+		expectedPaths[changedPath] = true
+		srv.NotifyChanged([]models.RelPathFromResultDir{models.RelPathFromResultDir(changedPath)})
+	}
+	reader := bufio.NewReader(resp.Body)
+	for len(expectedPaths) != 0 {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		delete(expectedPaths, strings.TrimSpace(strings.TrimPrefix(line, "data: ")))
+	}
+}
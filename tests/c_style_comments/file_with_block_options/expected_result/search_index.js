@@ -0,0 +1 @@
+window.DOCSNCODE_SEARCH_INDEX = {"documents":[{"path":"main.go.html","title":"main.go","headings":["Introduction","Details"],"text":"Introduction The block has an anchor and CSS classes Details The block is folded","identifiers":["func","main","package"]}],"terms":{"an":[0],"anchor":[0],"and":[0],"block":[0],"classes":[0],"css":[0],"details":[0],"folded":[0],"func":[0],"go":[0],"has":[0],"introduction":[0],"is":[0],"main":[0],"package":[0],"the":[0]}};
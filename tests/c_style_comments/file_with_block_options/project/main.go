@@ -0,0 +1,16 @@
+// @docsncode{id=intro class=note class=warning}
+// # Introduction
+// The block has an anchor and CSS classes
+// @docsncode
+package main
+
+/* @docsncode{collapsed}
+## Details
+The block is folded
+@docsncode */
+
+func main() {
+	// @docsncode{hidden}
+	// The block is not shown
+	// @docsncode
+}
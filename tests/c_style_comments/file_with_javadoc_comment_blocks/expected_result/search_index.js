@@ -0,0 +1 @@
+window.DOCSNCODE_SEARCH_INDEX = {"documents":[{"path":"Main.java.html","title":"Main.java","headings":["Main class"],"text":"Main class The gutter is not a part of the content: first item second item The terminator can be placed on its own line Lines starting with * at the block indent are a markdown list","identifiers":["Hello","Main","String","System","args","class","main","out","println","public","static","void"]}],"terms":{"are":[0],"args":[0],"at":[0],"be":[0],"block":[0],"can":[0],"class":[0],"content":[0],"first":[0],"gutter":[0],"hello":[0],"indent":[0],"is":[0],"item":[0],"its":[0],"java":[0],"line":[0],"lines":[0],"list":[0],"main":[0],"markdown":[0],"not":[0],"of":[0],"on":[0],"out":[0],"own":[0],"part":[0],"placed":[0],"println":[0],"public":[0],"second":[0],"starting":[0],"static":[0],"string":[0],"system":[0],"terminator":[0],"the":[0],"void":[0],"with":[0]}};
@@ -0,0 +1,20 @@
+/** @docsncode
+ * # Main class
+ *
+ * The gutter is not a part of the **content**:
+ * * first item
+ * * second item
+ * @docsncode */
+public class Main {
+    /* @docsncode
+     * The terminator can be placed on its own line
+     * @docsncode
+     */
+    public static void main(String[] args) {
+        /* @docsncode
+        * Lines starting with * at the block indent
+        * are a markdown list
+        @docsncode */
+        System.out.println("Hello");
+    }
+}
@@ -0,0 +1 @@
+window.DOCSNCODE_SEARCH_INDEX = {"documents":[{"path":"main.go.html","title":"main.go","text":"Multiline comment block","identifiers":["Hello","Println","fmt","func","import","main","package","world"]}],"terms":{"block":[0],"comment":[0],"fmt":[0],"func":[0],"go":[0],"hello":[0],"import":[0],"main":[0],"multiline":[0],"package":[0],"println":[0],"world":[0]}};
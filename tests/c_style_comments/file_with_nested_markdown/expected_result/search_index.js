@@ -0,0 +1 @@
+window.DOCSNCODE_SEARCH_INDEX = {"documents":[{"path":"main.go.html","title":"main.go","text":"Single line comment block:indented code first item nested item Multiline comment block:indented code first item nested item (indented with a tab)","identifiers":["func","main","package"]}],"terms":{"block":[0],"code":[0],"comment":[0],"first":[0],"func":[0],"go":[0],"indented":[0],"item":[0],"line":[0],"main":[0],"multiline":[0],"nested":[0],"package":[0],"single":[0],"tab":[0],"with":[0]}};
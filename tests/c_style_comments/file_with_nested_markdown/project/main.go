@@ -0,0 +1,21 @@
+// @docsncode
+// Single line comment block:
+//
+//     indented code
+//
+// * first item
+//   * nested item
+// @docsncode
+
+package main
+
+func main() {
+	/* @docsncode
+	Multiline comment block:
+
+	    indented code
+
+	* first item
+		* nested item (indented with a tab)
+	@docsncode */
+}
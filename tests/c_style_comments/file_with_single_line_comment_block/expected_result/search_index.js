@@ -0,0 +1 @@
+window.DOCSNCODE_SEARCH_INDEX = {"documents":[{"path":"file.txt.html","title":"file.txt","text":"Comment block"}],"terms":{"block":[0],"comment":[0],"file":[0],"txt":[0]}};
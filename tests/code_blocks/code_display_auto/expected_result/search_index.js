@@ -0,0 +1 @@
+window.DOCSNCODE_SEARCH_INDEX = {"documents":[{"path":"main.go.html","title":"main.go","text":"greet is long enough to be collapsed Short code blocks stay expanded","identifiers":["Hello","Println","fmt","func","greet","greeting","import","main","name","package","prints","string","the","world"]}],"terms":{"be":[0],"blocks":[0],"code":[0],"collapsed":[0],"enough":[0],"expanded":[0],"fmt":[0],"func":[0],"go":[0],"greet":[0],"greeting":[0],"hello":[0],"import":[0],"is":[0],"long":[0],"main":[0],"name":[0],"package":[0],"println":[0],"prints":[0],"short":[0],"stay":[0],"string":[0],"the":[0],"to":[0],"world":[0]}};
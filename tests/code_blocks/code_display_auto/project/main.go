@@ -0,0 +1,22 @@
+package main
+
+import "fmt"
+
+// @docsncode
+// `greet` is long enough to be collapsed
+// @docsncode
+
+// greet prints the greeting
+func greet(name string) {
+	if name == "" {
+		name = "world"
+	}
+	fmt.Println("Hello,", name)
+}
+
+// @docsncode
+// Short code blocks stay expanded
+// @docsncode
+func main() {
+	greet("")
+}
@@ -0,0 +1 @@
+window.DOCSNCODE_SEARCH_INDEX = {"documents":[{"path":"main.go.html","title":"main.go","text":"Run it with:go run main.go --name \"\u003cworld\u003e\" Usage example:greet(\"world\") Code without a language:plain text","identifiers":["Hello","Println","fmt","func","greet","import","main","name","package","string","world"]}],"terms":{"code":[0],"example":[0],"fmt":[0],"func":[0],"go":[0],"greet":[0],"hello":[0],"import":[0],"it":[0],"language":[0],"main":[0],"name":[0],"package":[0],"plain":[0],"println":[0],"run":[0],"string":[0],"text":[0],"usage":[0],"with":[0],"without":[0],"world":[0]}};
@@ -0,0 +1,28 @@
+// @docsncode
+// Run it with:
+// ```bash
+// go run main.go --name "<world>"
+// ```
+// @docsncode
+package main
+
+import "fmt"
+
+/* @docsncode
+Usage example:
+```go
+greet("world")
+```
+Code without a language:
+```
+plain text
+```
+@docsncode */
+
+func greet(name string) {
+	fmt.Println("Hello, " + name)
+}
+
+func main() {
+	greet("world")
+}
@@ -0,0 +1 @@
+window.DOCSNCODE_SEARCH_INDEX = {"documents":[{"path":"main.go.html","title":"main.go","text":"main only prints the greeting, error handling is hidden","identifiers":["Args","Exit","Hello","Println","This","args","block","code","comment","docsncode","fmt","func","hidden","import","len","main","many","package","the","too","unused","with"]}],"terms":{"args":[0],"block":[0],"code":[0],"comment":[0],"docsncode":[0],"error":[0],"exit":[0],"fmt":[0],"func":[0],"go":[0],"greeting":[0],"handling":[0],"hello":[0],"hidden":[0],"import":[0],"is":[0],"len":[0],"main":[0],"many":[0],"only":[0],"package":[0],"println":[0],"prints":[0],"the":[0],"this":[0],"too":[0],"unused":[0],"with":[0]}};
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// @docsncode
+// `main` only prints the greeting, error handling is hidden
+// @docsncode
+func main() {
+	// @docsncode-hide-start
+	if len(os.Args) > 2 {
+		fmt.Println("too many args")
+		os.Exit(1)
+	}
+	// @docsncode-hide-end
+	fmt.Println("Hello")
+}
+
+// @docsncode-hide-start
+func unused() {
+	// @docsncode
+	// This comment block is hidden with the code
+	// @docsncode
+}
+
+// @docsncode-hide-end
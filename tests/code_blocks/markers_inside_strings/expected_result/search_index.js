@@ -0,0 +1 @@
+window.DOCSNCODE_SEARCH_INDEX = {"documents":[{"path":"generate.sh.html","title":"generate.sh","text":"A real comment block","identifiers":["EOF_GO","Not","block","cat","comment","docsncode","done","echo","main","package"]},{"path":"main.go.html","title":"main.go","text":"The raw string below contains markers, but they are not comment blocks","identifiers":["Not","Print","block","comment","const","docsncode","either","fixture","fmt","func","import","inside","main","package","usual"]},{"path":"main.py.html","title":"main.py","text":"A real comment block","identifiers":["Not","TEMPLATE","block","comment","docsncode","print"]}],"terms":{"are":[1],"below":[1],"block":[0,1,2],"blocks":[1],"but":[1],"cat":[0],"comment":[0,1,2],"const":[1],"contains":[1],"docsncode":[0,1,2],"done":[0],"echo":[0],"either":[1],"eof":[0],"fixture":[1],"fmt":[1],"func":[1],"generate":[0],"go":[0,1],"import":[1],"inside":[1],"main":[0,1,2],"markers":[1],"not":[0,1,2],"package":[0,1],"print":[1,2],"py":[2],"raw":[1],"real":[0,2],"sh":[0],"string":[1],"template":[2],"the":[1],"they":[1],"usual":[1]}};
@@ -0,0 +1,11 @@
+cat > main.go <<'EOF_GO'
+// @docsncode
+// Not a comment block
+// @docsncode
+package main
+EOF_GO
+
+# @docsncode
+# A real comment block
+# @docsncode
+echo done
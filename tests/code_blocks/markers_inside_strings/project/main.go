@@ -0,0 +1,22 @@
+package main
+
+import "fmt"
+
+// @docsncode
+// The raw string below contains markers, but they are not comment blocks
+// @docsncode
+const fixture = `
+// @docsncode
+// Not a comment block
+// @docsncode
+`
+
+/*
+// @docsncode
+// Not a comment block either, it's inside a usual comment
+// @docsncode
+*/
+
+func main() {
+	fmt.Print(fixture)
+}
@@ -0,0 +1,10 @@
+TEMPLATE = """
+# @docsncode
+# Not a comment block
+# @docsncode
+"""
+
+# @docsncode
+# A real comment block
+# @docsncode
+print(TEMPLATE)
@@ -0,0 +1 @@
+window.DOCSNCODE_SEARCH_INDEX = {"documents":[{"path":"Main.hs.html","title":"Main.hs","text":"Single line comment block Multiline comment block","identifiers":["Hello","LANGUAGE","Main","OverloadedStrings","main","module","putStrLn","where"]}],"terms":{"block":[0],"comment":[0],"hello":[0],"hs":[0],"language":[0],"line":[0],"main":[0],"module":[0],"multiline":[0],"overloadedstrings":[0],"putstrln":[0],"single":[0],"where":[0]}};
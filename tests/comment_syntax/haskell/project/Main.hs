@@ -0,0 +1,12 @@
+{-# LANGUAGE OverloadedStrings #-}
+module Main where
+
+-- @docsncode
+-- Single line comment block
+-- @docsncode
+main :: IO ()
+main = putStrLn "Hello"
+
+{- @docsncode
+Multiline comment block
+@docsncode -}
@@ -0,0 +1 @@
+window.DOCSNCODE_SEARCH_INDEX = {"documents":[{"path":"main.lua.html","title":"main.lua","text":"Single line comment block Multiline comment block","identifiers":["Hello","end","function","greet","local","name","print","world"]}],"terms":{"block":[0],"comment":[0],"end":[0],"function":[0],"greet":[0],"hello":[0],"line":[0],"local":[0],"lua":[0],"main":[0],"multiline":[0],"name":[0],"print":[0],"single":[0],"world":[0]}};
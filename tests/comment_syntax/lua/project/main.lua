@@ -0,0 +1,11 @@
+-- @docsncode
+-- Single line comment block
+-- @docsncode
+local function greet(name)
+	print("Hello, " .. name)
+end
+
+--[[ @docsncode
+Multiline comment block
+@docsncode ]]
+greet("world")
@@ -0,0 +1 @@
+window.DOCSNCODE_SEARCH_INDEX = {"documents":[{"path":"main.pl.html","title":"main.pl","text":"Single line comment block POD comment block","identifiers":["Hello","greet","name","print","strict","sub","use","warnings","world"]}],"terms":{"block":[0],"comment":[0],"greet":[0],"hello":[0],"line":[0],"main":[0],"name":[0],"pl":[0],"pod":[0],"print":[0],"single":[0],"strict":[0],"sub":[0],"use":[0],"warnings":[0],"world":[0]}};
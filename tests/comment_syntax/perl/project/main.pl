@@ -0,0 +1,17 @@
+use strict;
+use warnings;
+
+# @docsncode
+# Single line comment block
+# @docsncode
+sub greet {
+	my ($name) = @_;
+	print "Hello, $name\n";
+}
+
+=pod @docsncode
+POD comment block
+@docsncode
+=cut
+
+greet("world");
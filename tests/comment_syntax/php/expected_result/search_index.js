@@ -0,0 +1 @@
+window.DOCSNCODE_SEARCH_INDEX = {"documents":[{"path":"main.php.html","title":"main.php","text":"C-style single line comment block Shell-style single line comment block Multiline comment block","identifiers":["Hello","echo","function","greet","name","php","world"]}],"terms":{"block":[0],"comment":[0],"echo":[0],"function":[0],"greet":[0],"hello":[0],"line":[0],"main":[0],"multiline":[0],"name":[0],"php":[0],"shell":[0],"single":[0],"style":[0],"world":[0]}};
@@ -0,0 +1,17 @@
+<?php
+
+// @docsncode
+// C-style single line comment block
+// @docsncode
+function greet($name) {
+	echo "Hello, $name\n";
+}
+
+# @docsncode
+# Shell-style single line comment block
+# @docsncode
+greet("world");
+
+/* @docsncode
+Multiline comment block
+@docsncode */
@@ -0,0 +1 @@
+window.DOCSNCODE_SEARCH_INDEX = {"documents":[{"path":"main.scm.html","title":"main.scm","text":"Single line comment block with several lines Multiline comment block","identifiers":["define","display","greet","name","world"]}],"terms":{"block":[0],"comment":[0],"define":[0],"display":[0],"greet":[0],"line":[0],"lines":[0],"main":[0],"multiline":[0],"name":[0],"scm":[0],"several":[0],"single":[0],"with":[0],"world":[0]}};
@@ -0,0 +1,11 @@
+;;; @docsncode
+;;; Single line comment block
+;;; with **several** lines
+;;; @docsncode
+(define (greet name)
+  (display name))
+
+#| @docsncode
+Multiline comment block
+@docsncode |#
+(greet "world")
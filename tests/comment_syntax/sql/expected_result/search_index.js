@@ -0,0 +1 @@
+window.DOCSNCODE_SEARCH_INDEX = {"documents":[{"path":"schema.sql.html","title":"schema.sql","headings":["Users"],"text":"Users Every user has a unique email. Multiline comment block","identifiers":["CREATE","FROM","INTEGER","KEY","NOT","NULL","PRIMARY","SELECT","TABLE","TEXT","UNIQUE","block","docsncode","email","not","users"]}],"terms":{"block":[0],"comment":[0],"create":[0],"docsncode":[0],"email":[0],"every":[0],"from":[0],"has":[0],"integer":[0],"key":[0],"multiline":[0],"not":[0],"null":[0],"primary":[0],"schema":[0],"select":[0],"sql":[0],"table":[0],"text":[0],"unique":[0],"user":[0],"users":[0]}};
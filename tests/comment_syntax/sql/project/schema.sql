@@ -0,0 +1,13 @@
+--- @docsncode
+--- # Users
+--- Every user has a unique email.
+--- @docsncode
+CREATE TABLE users (
+    id INTEGER PRIMARY KEY,
+    email TEXT NOT NULL UNIQUE -- not a @docsncode block
+);
+
+/* @docsncode
+Multiline comment block
+@docsncode */
+SELECT * FROM users;
@@ -0,0 +1 @@
+window.DOCSNCODE_SEARCH_INDEX = {"documents":[{"path":"main.tf.html","title":"main.tf","text":"Bucket for build artifacts Multiline comment block","identifiers":["artifacts","aws_s3_bucket","bucket","output","resource","value"]}],"terms":{"artifacts":[0],"aws":[0],"block":[0],"bucket":[0],"build":[0],"comment":[0],"for":[0],"main":[0],"multiline":[0],"output":[0],"resource":[0],"s3":[0],"tf":[0],"value":[0]}};
@@ -0,0 +1,13 @@
+# @doc
+# Bucket for **build artifacts**
+# @enddoc
+resource "aws_s3_bucket" "artifacts" {
+	bucket = "artifacts"
+}
+
+/* @doc
+Multiline comment block
+@enddoc */
+output "bucket" {
+	value = aws_s3_bucket.artifacts.id
+}
@@ -0,0 +1 @@
+window.DOCSNCODE_SEARCH_INDEX = {"documents":[{"path":"main.go.html","title":"main.go","text":"graph TD; A--\u003eB; A--\u003eC; B--\u003eD; C--\u003eD;","identifiers":["Hello","Println","fmt","func","import","main","package","world"]}],"terms":{"fmt":[0],"func":[0],"go":[0],"graph":[0],"hello":[0],"import":[0],"main":[0],"package":[0],"println":[0],"td":[0],"world":[0]}};
@@ -0,0 +1 @@
+window.DOCSNCODE_SEARCH_INDEX = {"documents":[{"path":"lib/math.go.html","title":"lib/math.go","identifiers":["Add","Counter","Inc","and","counter","counts","func","increments","int","lib","package","return","returns","struct","sum","the","type","value"]},{"path":"main.go.html","title":"main.go","headings":["Walkthrough"],"text":"Walkthrough The numbers are added by Add: @docsncode-include lib/math.go#Add Its receiver version lives in a method: @docsncode-include lib/math.go#Counter.Inc Lines can be included too: @docsncode-include lib/math.go#L3-L4","identifiers":["Println","See","fmt","func","import","lib","main","math","package"]}],"terms":{"add":[0,1],"added":[1],"and":[0],"are":[1],"be":[1],"by":[1],"can":[1],"counter":[0,1],"counts":[0],"docsncode":[1],"fmt":[1],"func":[0,1],"go":[0,1],"import":[1],"in":[1],"inc":[0,1],"include":[1],"included":[1],"increments":[0],"int":[0],"its":[1],"l3":[1],"l4":[1],"lib":[0,1],"lines":[1],"lives":[1],"main":[1],"math":[0,1],"method":[1],"numbers":[1],"package":[0,1],"println":[1],"receiver":[1],"return":[0],"returns":[0],"see":[1],"struct":[0],"sum":[0],"the":[0,1],"too":[1],"type":[0],"value":[0],"version":[1],"walkthrough":[1]}};
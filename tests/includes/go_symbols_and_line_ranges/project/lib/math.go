@@ -0,0 +1,16 @@
+package lib
+
+// Counter counts
+type Counter struct {
+	value int
+}
+
+// Add returns the sum of a and b
+func Add(a, b int) int {
+	return a + b
+}
+
+// Inc increments the counter
+func (c *Counter) Inc() {
+	c.value++
+}
@@ -0,0 +1,17 @@
+package main
+
+import "fmt"
+
+// @docsncode
+// # Walkthrough
+// The numbers are added by `Add`:
+// @docsncode-include lib/math.go#Add
+// Its receiver version lives in a method:
+// @docsncode-include lib/math.go#Counter.Inc
+//
+// Lines can be included too:
+// @docsncode-include lib/math.go#L3-L4
+// @docsncode
+func main() {
+	fmt.Println("See lib/math.go")
+}
@@ -0,0 +1 @@
+window.DOCSNCODE_SEARCH_INDEX = {"documents":[{"path":"lib/math/sum.go.html","title":"lib/math/sum.go","identifiers":["Sum","func","int","math","package","return"]},{"path":"main.go.html","title":"main.go","text":"Entry point","identifiers":["func","main","package"]}],"terms":{"entry":[1],"func":[0,1],"go":[0,1],"int":[0],"lib":[0],"main":[1],"math":[0],"package":[0,1],"point":[1],"return":[0],"sum":[0]}};
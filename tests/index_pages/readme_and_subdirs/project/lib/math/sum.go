@@ -0,0 +1,5 @@
+package math
+
+func Sum(a, b int) int {
+	return a + b
+}
@@ -0,0 +1,6 @@
+package main
+
+// @docsncode
+// Entry point
+// @docsncode
+func main() {}
@@ -0,0 +1 @@
+window.DOCSNCODE_SEARCH_INDEX = {"documents":[{"path":"main.go.html","title":"main.go","headings":["Greeting","Entry point"],"text":"Greeting greet builds the greeting for the name Entry point main prints the greeting","identifiers":["Hello","Println","fmt","func","greet","import","main","name","package","return","string","world"]}],"terms":{"builds":[0],"entry":[0],"fmt":[0],"for":[0],"func":[0],"go":[0],"greet":[0],"greeting":[0],"hello":[0],"import":[0],"main":[0],"name":[0],"package":[0],"point":[0],"println":[0],"prints":[0],"return":[0],"string":[0],"the":[0],"world":[0]}};
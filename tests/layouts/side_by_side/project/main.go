@@ -0,0 +1,21 @@
+package main
+
+import "fmt"
+
+// @docsncode
+// ## Greeting
+// `greet` builds the greeting for the name
+// @docsncode
+func greet(name string) string {
+	return "Hello, " + name
+}
+
+// @docsncode
+// ## Entry point
+// @docsncode
+// @docsncode
+// `main` prints the greeting
+// @docsncode
+func main() {
+	fmt.Println(greet("world"))
+}
@@ -0,0 +1 @@
+window.DOCSNCODE_SEARCH_INDEX = {"documents":[{"path":"main.go.html","title":"main.go","text":"sum is defined in these lines, it's called here","identifiers":["Println","fmt","func","import","main","package","sum"]},{"path":"sum.go.html","title":"sum.go","identifiers":["func","int","main","package","return","sum"]}],"terms":{"called":[0],"defined":[0],"fmt":[0],"func":[0,1],"go":[0,1],"here":[0],"import":[0],"in":[0],"int":[1],"is":[0],"it":[0],"lines":[0],"main":[0,1],"package":[0,1],"println":[0],"return":[1],"sum":[0,1],"these":[0]}};
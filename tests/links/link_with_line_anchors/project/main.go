@@ -0,0 +1,12 @@
+package main
+
+import "fmt"
+
+// @docsncode
+// [sum](sum.go#L3) is defined in [these lines](sum.go#L3-L5),
+// it's called [here](#L11)
+// @docsncode
+
+func main() {
+	fmt.Println(sum(1, 2))
+}
@@ -0,0 +1 @@
+window.DOCSNCODE_SEARCH_INDEX = {"documents":[{"path":"main.go.html","title":"main.go","text":"link","identifiers":["Hello","Println","fmt","func","import","main","package","world"]},{"path":"sum.go.html","title":"sum.go","identifiers":["func","int","main","package","return","sum"]}],"terms":{"fmt":[0],"func":[0,1],"go":[0,1],"hello":[0],"import":[0],"int":[1],"link":[0],"main":[0,1],"package":[0,1],"println":[0],"return":[1],"sum":[1],"world":[0]}};
@@ -0,0 +1 @@
+window.DOCSNCODE_SEARCH_INDEX = {"documents":[{"path":"main.py.html","title":"main.py","headings":["Module docstring"],"text":"Module docstring It's rendered as markdown. Indented function docstring with a raw prefix","identifiers":["__main__","__name__","block","def","docsncode","main","not","print"]}],"terms":{"as":[0],"block":[0],"def":[0],"docsncode":[0],"docstring":[0],"function":[0],"indented":[0],"it":[0],"main":[0],"markdown":[0],"module":[0],"name":[0],"not":[0],"prefix":[0],"print":[0],"py":[0],"raw":[0],"rendered":[0],"with":[0]}};
@@ -0,0 +1,17 @@
+"""@docsncode
+# Module docstring
+
+It's rendered as **markdown**.
+@docsncode"""
+
+
+def main():
+    r'''@docsncode
+    Indented function docstring with a `raw` prefix
+    @docsncode
+    '''
+    print("""not a @docsncode block""")
+
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1 @@
+window.DOCSNCODE_SEARCH_INDEX = {"documents":[{"path":"main.py.html","title":"main.py","text":"Comment block","identifiers":["Hello","__main__","__name__","def","main","print","world"]}],"terms":{"block":[0],"comment":[0],"def":[0],"hello":[0],"main":[0],"name":[0],"print":[0],"py":[0],"world":[0]}};